@@ -0,0 +1,69 @@
+// Package barneshut approximates n-body interactions on top of a qtree.QuadTree.
+// Every node of the tree carries its total mass and centre of mass, so groups of
+// distant bodies can be treated as a single pseudo-body, reducing a force pass
+// from O(n²) to roughly O(n log n).
+package barneshut
+
+import (
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokg/pkg/plane"
+	"github.com/kjkrol/gokq/pkg/qtree"
+)
+
+// Body is a tree item with a mass. Items stored in the tree that do not
+// implement Body are treated as having unit mass.
+type Body[T geom.Numeric] interface {
+	qtree.Item[T]
+	Mass() float64
+}
+
+// MassMoment is the per-node summary maintained by MassAggregator: the total
+// mass of a subtree and the mass-weighted sum of its body centres.
+type MassMoment struct {
+	Mass   float64
+	Moment geom.Vec[float64]
+}
+
+// CenterOfMass returns the centre of mass of the summarized bodies.
+func (m MassMoment) CenterOfMass() geom.Vec[float64] {
+	if m.Mass == 0 {
+		return geom.Vec[float64]{}
+	}
+	return geom.NewVec(m.Moment.X/m.Mass, m.Moment.Y/m.Mass)
+}
+
+// MassAggregator keeps MassMoment summaries up to date on every tree node.
+type MassAggregator[T geom.Numeric] struct{}
+
+func (MassAggregator[T]) Empty() MassMoment { return MassMoment{} }
+
+func (MassAggregator[T]) Lift(item qtree.Item[T]) MassMoment {
+	mass := massOf(item)
+	center := centerOf(item.Bound())
+	return MassMoment{Mass: mass, Moment: geom.NewVec(center.X*mass, center.Y*mass)}
+}
+
+func (MassAggregator[T]) Merge(a, b MassMoment) MassMoment {
+	return MassMoment{Mass: a.Mass + b.Mass, Moment: a.Moment.Add(b.Moment)}
+}
+
+// NewTree builds a quadtree over space that maintains the mass summaries the
+// Solver relies on.
+func NewTree[T geom.Numeric](space plane.Space2D[T], opts ...qtree.QuadTreeOption[T]) *qtree.QuadTree[T] {
+	opts = append(opts, qtree.WithAggregator[T, MassMoment](MassAggregator[T]{}))
+	return qtree.NewQuadTree(space, opts...)
+}
+
+func massOf[T geom.Numeric](item qtree.Item[T]) float64 {
+	if body, ok := item.(Body[T]); ok {
+		return body.Mass()
+	}
+	return 1
+}
+
+func centerOf[T geom.Numeric](box geom.AABB[T]) geom.Vec[float64] {
+	return geom.NewVec(
+		(float64(box.TopLeft.X)+float64(box.BottomRight.X))/2,
+		(float64(box.TopLeft.Y)+float64(box.BottomRight.Y))/2,
+	)
+}
@@ -0,0 +1,140 @@
+package barneshut
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokg/pkg/plane"
+	"github.com/kjkrol/gokq/pkg/qtree"
+)
+
+type testBody struct {
+	geom.AABB[float64]
+	id   int
+	mass float64
+}
+
+func (b *testBody) Bound() geom.AABB[float64] { return b.AABB }
+func (b *testBody) Mass() float64             { return b.mass }
+func (b *testBody) SameID(other qtree.Item[float64]) bool {
+	o, ok := other.(*testBody)
+	return ok && o.id == b.id
+}
+
+func newTestBody(id int, x, y, mass float64) *testBody {
+	return &testBody{AABB: geom.NewAABBAround(geom.NewVec(x, y), 0), id: id, mass: mass}
+}
+
+func randomBodies(tree *qtree.QuadTree[float64], rnd *rand.Rand, n int, size float64) []Body[float64] {
+	bodies := make([]Body[float64], 0, n)
+	for i := range n {
+		body := newTestBody(i, rnd.Float64()*size, rnd.Float64()*size, 0.5+rnd.Float64())
+		tree.Add(body)
+		bodies = append(bodies, body)
+	}
+	return bodies
+}
+
+func bruteForce(solver *Solver[float64], bodies []Body[float64], body Body[float64]) geom.Vec[float64] {
+	force := geom.Vec[float64]{}
+	position := centerOf(body.Bound())
+	for _, other := range bodies {
+		if other.SameID(body) {
+			continue
+		}
+		force.AddMutable(solver.interaction(position, body.Mass(), centerOf(other.Bound()), other.Mass()))
+	}
+	return force
+}
+
+func TestSolver_ZeroThetaIsExact(t *testing.T) {
+	for _, space := range []plane.Space2D[float64]{
+		plane.NewEuclidean2D(100.0, 100.0),
+		plane.NewToroidal2D(100.0, 100.0),
+	} {
+		t.Run(space.Name(), func(t *testing.T) {
+			tree := NewTree(space)
+			defer tree.Close()
+			bodies := randomBodies(tree, rand.New(rand.NewSource(1)), 200, 100)
+			solver := NewSolver(tree, space, WithTheta[float64](0))
+
+			for i, force := range solver.Forces(bodies) {
+				expected := bruteForce(solver, bodies, bodies[i])
+				if math.Abs(force.X-expected.X) > 1e-9 || math.Abs(force.Y-expected.Y) > 1e-9 {
+					t.Fatalf("body %d: force %v, expected %v", i, force, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestSolver_ApproximationStaysClose(t *testing.T) {
+	for _, space := range []plane.Space2D[float64]{
+		plane.NewEuclidean2D(1000.0, 1000.0),
+		plane.NewToroidal2D(1000.0, 1000.0),
+	} {
+		t.Run(space.Name(), func(t *testing.T) {
+			tree := NewTree(space)
+			defer tree.Close()
+			bodies := randomBodies(tree, rand.New(rand.NewSource(2)), 1000, 1000)
+			solver := NewSolver(tree, space, WithTheta[float64](0.5), WithSoftening[float64](1))
+
+			var errSum, normSum float64
+			for i, force := range solver.Forces(bodies) {
+				expected := bruteForce(solver, bodies, bodies[i])
+				errSum += math.Hypot(force.X-expected.X, force.Y-expected.Y)
+				normSum += math.Hypot(expected.X, expected.Y)
+			}
+			if errSum == 0 {
+				t.Errorf("expected distant nodes to be approximated")
+			}
+			if relative := errSum / normSum; relative > 0.05 {
+				t.Errorf("mean relative error %.4f exceeds 5%%", relative)
+			}
+		})
+	}
+}
+
+func TestSolver_AttractsAcrossToroidalSeam(t *testing.T) {
+	space := plane.NewToroidal2D(100.0, 100.0)
+	tree := NewTree(space)
+	defer tree.Close()
+
+	left := newTestBody(1, 1, 50, 1)
+	right := newTestBody(2, 99, 50, 1)
+	tree.Add(left)
+	tree.Add(right)
+
+	force := NewSolver(tree, space).Force(left)
+	if force.X >= 0 {
+		t.Errorf("expected the left body to be pulled across the seam (negative X), got %v", force)
+	}
+
+	repulsion := NewSolver(tree, space, WithGravity[float64](-1)).Force(left)
+	if repulsion.X <= 0 {
+		t.Errorf("expected negative gravity to push the body away from the seam, got %v", repulsion)
+	}
+}
+
+func ExampleSolver_Force() {
+	space := plane.NewEuclidean2D(100.0, 100.0)
+	tree := NewTree(space)
+	defer tree.Close()
+
+	probe := newTestBody(0, 10, 50, 1)
+	tree.Add(probe)
+	// A distant cluster of four unit masses is approximated by its centre of mass.
+	for i, pos := range []geom.Vec[float64]{{X: 89, Y: 49}, {X: 91, Y: 49}, {X: 89, Y: 51}, {X: 91, Y: 51}} {
+		tree.Add(newTestBody(i+1, pos.X, pos.Y, 1))
+	}
+
+	solver := NewSolver(tree, space, WithSoftening[float64](0))
+	force := solver.Force(probe)
+	fmt.Printf("fx=%.6f |fy|<1e-12: %v\n", force.X, math.Abs(force.Y) < 1e-12)
+
+	// Output:
+	// fx=0.000625 |fy|<1e-12: true
+}
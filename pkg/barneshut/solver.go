@@ -0,0 +1,182 @@
+package barneshut
+
+import (
+	"math"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokg/pkg/plane"
+	"github.com/kjkrol/gokq/pkg/qtree"
+)
+
+const (
+	DEFAULT_THETA     float64 = 0.5
+	DEFAULT_GRAVITY   float64 = 1
+	DEFAULT_SOFTENING float64 = 1e-3
+)
+
+// Solver evaluates approximate pairwise forces between the bodies stored in a
+// tree built by NewTree. The force exerted by a body of mass m2 on a body of
+// mass m1 is G·m1·m2·d/(|d|²+ε²)^(3/2), where d points from the first body to
+// the second; a negative G turns attraction into repulsion.
+type Solver[T geom.Numeric] struct {
+	tree      *qtree.QuadTree[T]
+	theta     float64
+	gravity   float64
+	softening float64
+	cyclic    bool
+	size      geom.Vec[float64]
+}
+
+type SolverOption[T geom.Numeric] func(*Solver[T])
+
+// WithTheta sets the opening angle: a node is approximated by its centre of mass
+// when its width divided by the distance to that centre is below theta. Zero
+// disables the approximation and yields the exact O(n²) sum.
+func WithTheta[T geom.Numeric](theta float64) SolverOption[T] {
+	return func(s *Solver[T]) {
+		if theta >= 0 {
+			s.theta = theta
+		}
+	}
+}
+
+// WithGravity sets the interaction constant G.
+func WithGravity[T geom.Numeric](gravity float64) SolverOption[T] {
+	return func(s *Solver[T]) {
+		s.gravity = gravity
+	}
+}
+
+// WithSoftening sets ε, which keeps forces finite for coincident bodies.
+func WithSoftening[T geom.Numeric](softening float64) SolverOption[T] {
+	return func(s *Solver[T]) {
+		if softening >= 0 {
+			s.softening = softening
+		}
+	}
+}
+
+// NewSolver creates a solver reading the tree built by NewTree over space. On
+// toroidal spaces displacements follow the minimum-image convention, so bodies
+// interact across the wrapped edges.
+func NewSolver[T geom.Numeric](
+	tree *qtree.QuadTree[T],
+	space plane.Space2D[T],
+	opts ...SolverOption[T],
+) *Solver[T] {
+	viewport := space.Viewport()
+	s := &Solver[T]{
+		tree:      tree,
+		theta:     DEFAULT_THETA,
+		gravity:   DEFAULT_GRAVITY,
+		softening: DEFAULT_SOFTENING,
+		cyclic:    qtree.IsCyclic(space),
+		size: geom.NewVec(
+			float64(viewport.BottomRight.X)-float64(viewport.TopLeft.X),
+			float64(viewport.BottomRight.Y)-float64(viewport.TopLeft.Y),
+		),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Force returns the total force acting on body. The body itself is skipped when
+// it is stored in the tree.
+func (s *Solver[T]) Force(body Body[T]) geom.Vec[float64] {
+	position := centerOf(body.Bound())
+	mass := body.Mass()
+	force := geom.Vec[float64]{}
+
+	s.tree.VisitNodes(func(node qtree.NodeView[T]) bool {
+		summary, _ := qtree.NodeViewSummary[T, MassMoment](node)
+		if summary.Mass == 0 {
+			return false
+		}
+		if !node.IsLeaf() && s.canApproximate(node.LooseBounds(), summary, position) {
+			force.AddMutable(s.interaction(position, mass, summary.CenterOfMass(), summary.Mass))
+			return false
+		}
+		for item := range node.Items() {
+			if item.SameID(body) {
+				continue
+			}
+			force.AddMutable(s.interaction(position, mass, centerOf(item.Bound()), massOf(item)))
+		}
+		return true
+	})
+
+	return force
+}
+
+// Forces evaluates Force for every body, preserving the input order.
+func (s *Solver[T]) Forces(bodies []Body[T]) []geom.Vec[float64] {
+	forces := make([]geom.Vec[float64], len(bodies))
+	for i, body := range bodies {
+		forces[i] = s.Force(body)
+	}
+	return forces
+}
+
+// canApproximate applies the opening criterion. Nodes containing the evaluated
+// position are always opened, and on cyclic planes so are nodes wider than half
+// the plane, whose minimum image is ambiguous.
+func (s *Solver[T]) canApproximate(bounds geom.AABB[T], summary MassMoment, position geom.Vec[float64]) bool {
+	if s.theta == 0 {
+		return false
+	}
+	width := math.Max(
+		float64(bounds.BottomRight.X)-float64(bounds.TopLeft.X),
+		float64(bounds.BottomRight.Y)-float64(bounds.TopLeft.Y),
+	)
+	if s.cyclic && (2*width > s.size.X || 2*width > s.size.Y) {
+		return false
+	}
+	if position.X >= float64(bounds.TopLeft.X) && position.X <= float64(bounds.BottomRight.X) &&
+		position.Y >= float64(bounds.TopLeft.Y) && position.Y <= float64(bounds.BottomRight.Y) {
+		return false
+	}
+	d := s.displacement(position, summary.CenterOfMass())
+	distance := math.Hypot(d.X, d.Y)
+	return distance > 0 && width/distance < s.theta
+}
+
+func (s *Solver[T]) interaction(
+	position geom.Vec[float64],
+	mass float64,
+	other geom.Vec[float64],
+	otherMass float64,
+) geom.Vec[float64] {
+	d := s.displacement(position, other)
+	distSq := d.X*d.X + d.Y*d.Y + s.softening*s.softening
+	if distSq == 0 {
+		return geom.Vec[float64]{}
+	}
+	scale := s.gravity * mass * otherMass / (distSq * math.Sqrt(distSq))
+	return geom.NewVec(d.X*scale, d.Y*scale)
+}
+
+// displacement returns the vector from -> to, folded to the nearest periodic
+// image on cyclic planes.
+func (s *Solver[T]) displacement(from, to geom.Vec[float64]) geom.Vec[float64] {
+	d := to.Sub(from)
+	if s.cyclic {
+		d.X = nearestImage(d.X, s.size.X)
+		d.Y = nearestImage(d.Y, s.size.Y)
+	}
+	return d
+}
+
+func nearestImage(delta, size float64) float64 {
+	if size == 0 {
+		return delta
+	}
+	delta = math.Mod(delta, size)
+	if delta > size/2 {
+		delta -= size
+	} else if delta < -size/2 {
+		delta += size
+	}
+	return delta
+}
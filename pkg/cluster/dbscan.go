@@ -0,0 +1,108 @@
+// Package cluster groups the items of a qtree.QuadTree by spatial density,
+// using the tree itself as the region-query index.
+package cluster
+
+import (
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokq/pkg/qtree"
+)
+
+// NOISE labels items that belong to no cluster.
+const NOISE int = -1
+
+const unvisited int = -2
+
+// Result assigns every item of the tree to a cluster. Items holds the tree
+// contents in AllItems order and Labels[i] is the cluster of Items[i]: a value
+// in [0, Clusters) or NOISE.
+type Result[T geom.Numeric] struct {
+	Items    []qtree.Item[T]
+	Labels   []int
+	Clusters int
+	index    map[qtree.Item[T]]int
+}
+
+// Label returns the cluster assigned to item; the second result is false when
+// the item was not part of the clustered tree.
+func (r Result[T]) Label(item qtree.Item[T]) (int, bool) {
+	i, ok := r.index[item]
+	if !ok {
+		return NOISE, false
+	}
+	return r.Labels[i], true
+}
+
+// Members returns the items assigned to cluster (or NOISE), in AllItems order.
+func (r Result[T]) Members(cluster int) []qtree.Item[T] {
+	members := []qtree.Item[T]{}
+	for i, label := range r.Labels {
+		if label == cluster {
+			members = append(members, r.Items[i])
+		}
+	}
+	return members
+}
+
+// DBSCAN clusters the tree contents with the density-based DBSCAN algorithm.
+// An item is a core item when at least minPts items, itself included, lie
+// within eps of it as measured by FindNeighbors; clusters are the connected
+// components of core items together with the border items they reach. The
+// neighbourhood follows the tree's plane, so clusters on a toroidal plane join
+// across the wrapped edges. Items must be comparable, as required by Remove.
+func DBSCAN[T geom.Numeric](tree *qtree.QuadTree[T], eps T, minPts int) Result[T] {
+	items := tree.AllItems()
+	result := Result[T]{
+		Items:  items,
+		Labels: make([]int, len(items)),
+		index:  make(map[qtree.Item[T]]int, len(items)),
+	}
+	for i, item := range items {
+		result.Labels[i] = unvisited
+		result.index[item] = i
+	}
+
+	// The region query runs once per item; its result decides whether the item
+	// is a core item and, if so, is what the cluster expands through.
+	isCore := func(neighbors []qtree.Item[T]) bool {
+		return len(neighbors)+1 >= minPts
+	}
+
+	for i, item := range items {
+		if result.Labels[i] != unvisited {
+			continue
+		}
+		queue := tree.FindNeighbors(item, eps)
+		if !isCore(queue) {
+			result.Labels[i] = NOISE
+			continue
+		}
+
+		cluster := result.Clusters
+		result.Clusters++
+		result.Labels[i] = cluster
+
+		for len(queue) > 0 {
+			next := queue[len(queue)-1]
+			queue = queue[:len(queue)-1]
+
+			j, ok := result.index[next]
+			if !ok {
+				continue
+			}
+			switch result.Labels[j] {
+			case NOISE:
+				result.Labels[j] = cluster
+				continue
+			case unvisited:
+				result.Labels[j] = cluster
+			default:
+				continue
+			}
+			if neighbors := tree.FindNeighbors(next, eps); isCore(neighbors) {
+				queue = append(queue, neighbors...)
+			}
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,120 @@
+package cluster
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokg/pkg/plane"
+	"github.com/kjkrol/gokq/pkg/qtree"
+)
+
+type unit struct {
+	geom.AABB[float64]
+	name string
+}
+
+func (u *unit) Bound() geom.AABB[float64] { return u.AABB }
+func (u *unit) SameID(other qtree.Item[float64]) bool {
+	o, ok := other.(*unit)
+	return ok && o.name == u.name
+}
+func (u *unit) String() string { return u.name }
+
+func newUnit(name string, x, y float64) *unit {
+	return &unit{AABB: geom.NewAABBAround(geom.NewVec(x, y), 0), name: name}
+}
+
+func addUnits(tree *qtree.QuadTree[float64], units ...*unit) {
+	for _, u := range units {
+		tree.Add(u)
+	}
+}
+
+func TestDBSCAN_SeparatesSquadsAndNoise(t *testing.T) {
+	tree := qtree.NewQuadTree(plane.NewEuclidean2D(100.0, 100.0))
+	defer tree.Close()
+
+	alpha := []*unit{newUnit("a1", 10, 10), newUnit("a2", 11, 10), newUnit("a3", 10, 11), newUnit("a4", 11, 11)}
+	bravo := []*unit{newUnit("b1", 80, 80), newUnit("b2", 81, 81), newUnit("b3", 82, 80)}
+	border := newUnit("border", 13, 11) // within eps of a4 only
+	loner := newUnit("loner", 50, 50)
+	addUnits(tree, alpha...)
+	addUnits(tree, bravo...)
+	addUnits(tree, border, loner)
+
+	result := DBSCAN(tree, 2.0, 3)
+
+	if result.Clusters != 2 {
+		t.Fatalf("expected 2 clusters, got %d", result.Clusters)
+	}
+	alphaLabel, _ := result.Label(alpha[0])
+	for _, u := range append(alpha, border) {
+		if label, _ := result.Label(u); label != alphaLabel {
+			t.Errorf("%s labelled %d, expected %d", u, label, alphaLabel)
+		}
+	}
+	bravoLabel, _ := result.Label(bravo[0])
+	if bravoLabel == alphaLabel {
+		t.Errorf("expected squads to form separate clusters")
+	}
+	for _, u := range bravo {
+		if label, _ := result.Label(u); label != bravoLabel {
+			t.Errorf("%s labelled %d, expected %d", u, label, bravoLabel)
+		}
+	}
+	if label, _ := result.Label(loner); label != NOISE {
+		t.Errorf("expected loner to be noise, got %d", label)
+	}
+	if _, ok := result.Label(newUnit("stranger", 1, 1)); ok {
+		t.Errorf("expected unknown item to be reported as missing")
+	}
+}
+
+func TestDBSCAN_JoinsAcrossToroidalEdges(t *testing.T) {
+	for _, tc := range []struct {
+		space    plane.Space2D[float64]
+		clusters int
+	}{
+		{space: plane.NewEuclidean2D(100.0, 100.0), clusters: 2},
+		{space: plane.NewToroidal2D(100.0, 100.0), clusters: 1},
+	} {
+		t.Run(tc.space.Name(), func(t *testing.T) {
+			tree := qtree.NewQuadTree(tc.space)
+			defer tree.Close()
+
+			addUnits(tree,
+				newUnit("w1", 0, 50), newUnit("w2", 1, 50), newUnit("w3", 0.5, 51),
+				newUnit("e1", 99, 50), newUnit("e2", 98.5, 51), newUnit("e3", 99.5, 49),
+			)
+
+			result := DBSCAN(tree, 1.5, 3)
+			if result.Clusters != tc.clusters {
+				t.Errorf("expected %d clusters, got %d", tc.clusters, result.Clusters)
+			}
+			if noise := result.Members(NOISE); len(noise) != 0 {
+				t.Errorf("expected no noise, got %v", noise)
+			}
+		})
+	}
+}
+
+func ExampleDBSCAN() {
+	tree := qtree.NewQuadTree(plane.NewToroidal2D(64.0, 64.0))
+	defer tree.Close()
+
+	addUnits(tree,
+		newUnit("scout", 32, 32),
+		newUnit("n1", 1, 1), newUnit("n2", 63, 1), newUnit("n3", 1, 63), newUnit("n4", 63, 63),
+	)
+
+	result := DBSCAN(tree, 3.0, 4)
+	for cluster := range result.Clusters {
+		fmt.Println(cluster, result.Members(cluster))
+	}
+	fmt.Println("noise", result.Members(NOISE))
+
+	// Output:
+	// 0 [n1 n2 n3 n4]
+	// noise [scout]
+}
@@ -0,0 +1,102 @@
+package dfs
+
+import "cmp"
+
+// PriorityFunc ranks a node for BestFirst; lower priorities are visited first.
+type PriorityFunc[N any, P cmp.Ordered] func(node N) P
+
+// BestFirstVisitFunc processes a node popped by BestFirst together with its
+// priority.
+type BestFirstVisitFunc[N any, P cmp.Ordered] func(node N, priority P) DFSControl
+
+// BestFirst visits the structure rooted at root in increasing order of
+// priority, queueing the children of every visited node in a min-heap. With a
+// priority that never decreases from a node to its children, such as the
+// distance from a query point to a node's bounds, a kNN query such as
+// qtree.QuadTree.Nearest can return Stop once the popped priority exceeds the
+// k-th best distance found so far: every node still queued is at least as far. SkipChildren keeps a node's
+// children out of the queue. Nodes of equal priority are visited in the order
+// they were queued. BestFirst reports whether visit returned Stop and how many
+// nodes were visited.
+func BestFirst[N ChildCarrier[N], P cmp.Ordered](
+	root N,
+	priority PriorityFunc[N, P],
+	visit BestFirstVisitFunc[N, P],
+) (stopped bool, visited int) {
+	queue := &priorityQueue[N, P]{}
+	queue.push(root, priority(root))
+	for queue.Len() > 0 {
+		entry := queue.pop()
+		visited++
+
+		switch visit(entry.node, entry.priority) {
+		case Stop:
+			return true, visited
+		case SkipChildren:
+			continue
+		}
+
+		for _, child := range entry.node.Children() {
+			queue.push(child, priority(child))
+		}
+	}
+	return false, visited
+}
+
+type prioritized[N any, P cmp.Ordered] struct {
+	node     N
+	priority P
+	seq      int
+}
+
+// priorityQueue is a binary min-heap of nodes ordered by priority and then by
+// the order in which they were pushed. It is typed, unlike container/heap, so
+// pushing and popping does not box the entries.
+type priorityQueue[N any, P cmp.Ordered] struct {
+	entries []prioritized[N, P]
+	seq     int
+}
+
+func (q *priorityQueue[N, P]) Len() int { return len(q.entries) }
+
+func (q *priorityQueue[N, P]) less(i, j int) bool {
+	a, b := &q.entries[i], &q.entries[j]
+	if a.priority != b.priority {
+		return a.priority < b.priority
+	}
+	return a.seq < b.seq
+}
+
+func (q *priorityQueue[N, P]) push(node N, priority P) {
+	q.entries = append(q.entries, prioritized[N, P]{node: node, priority: priority, seq: q.seq})
+	q.seq++
+	for i := len(q.entries) - 1; i > 0; {
+		parent := (i - 1) / 2
+		if !q.less(i, parent) {
+			break
+		}
+		q.entries[i], q.entries[parent] = q.entries[parent], q.entries[i]
+		i = parent
+	}
+}
+
+func (q *priorityQueue[N, P]) pop() prioritized[N, P] {
+	top := q.entries[0]
+	last := len(q.entries) - 1
+	q.entries[0] = q.entries[last]
+	q.entries = q.entries[:last]
+	for i := 0; ; {
+		smallest := i
+		for _, child := range [2]int{2*i + 1, 2*i + 2} {
+			if child < last && q.less(child, smallest) {
+				smallest = child
+			}
+		}
+		if smallest == i {
+			break
+		}
+		q.entries[i], q.entries[smallest] = q.entries[smallest], q.entries[i]
+		i = smallest
+	}
+	return top
+}
@@ -0,0 +1,94 @@
+package dfs
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+type weightedNode struct {
+	label    string
+	weight   int
+	children []*weightedNode
+}
+
+func (n *weightedNode) Children() []*weightedNode { return n.children }
+
+func newWeightedTree() *weightedNode {
+	return &weightedNode{label: "root", children: []*weightedNode{
+		{label: "a", weight: 5, children: []*weightedNode{{label: "a1", weight: 6}, {label: "a2", weight: 9}}},
+		{label: "b", weight: 2, children: []*weightedNode{{label: "b1", weight: 7}, {label: "b2", weight: 3}}},
+		{label: "c", weight: 5},
+	}}
+}
+
+func TestBestFirst(t *testing.T) {
+	tests := []struct {
+		name        string
+		controls    map[string]DFSControl
+		wantOrder   []string
+		wantStopped bool
+	}{
+		{
+			name:      "lowest priority first, ties in queueing order",
+			wantOrder: []string{"root", "b", "b2", "a", "c", "a1", "b1", "a2"},
+		},
+		{
+			name:      "skip children",
+			controls:  map[string]DFSControl{"b": SkipChildren},
+			wantOrder: []string{"root", "b", "a", "c", "a1", "a2"},
+		},
+		{
+			name:        "stop",
+			controls:    map[string]DFSControl{"a": Stop},
+			wantOrder:   []string{"root", "b", "b2", "a"},
+			wantStopped: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := []string{}
+			stopped, visited := BestFirst(newWeightedTree(),
+				func(node *weightedNode) int { return node.weight },
+				func(node *weightedNode, priority int) DFSControl {
+					if priority != node.weight {
+						t.Errorf("%s visited with priority %d, expected %d", node.label, priority, node.weight)
+					}
+					order = append(order, node.label)
+					return tt.controls[node.label]
+				},
+			)
+
+			if !slices.Equal(order, tt.wantOrder) {
+				t.Errorf("visit order = %v, expected %v", order, tt.wantOrder)
+			}
+			if stopped != tt.wantStopped {
+				t.Errorf("stopped = %v, expected %v", stopped, tt.wantStopped)
+			}
+			if visited != len(tt.wantOrder) {
+				t.Errorf("visited = %d, expected %d", visited, len(tt.wantOrder))
+			}
+		})
+	}
+}
+
+// ExampleBestFirst finds the cheapest leaf: with priorities that never decrease
+// towards the leaves, the first leaf popped is the best one.
+func ExampleBestFirst() {
+	var cheapest *weightedNode
+	BestFirst(newWeightedTree(),
+		func(node *weightedNode) int { return node.weight },
+		func(node *weightedNode, _ int) DFSControl {
+			if len(node.children) == 0 {
+				cheapest = node
+				return Stop
+			}
+			return Continue
+		},
+	)
+	fmt.Println(cheapest.label, cheapest.weight)
+
+	// Output:
+	// b2 3
+}
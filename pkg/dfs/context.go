@@ -0,0 +1,31 @@
+package dfs
+
+import "context"
+
+// CONTEXT_CHECK_INTERVAL is the number of nodes DFSContext visits between two
+// checks of its context, keeping the overhead of cancellation support low.
+const CONTEXT_CHECK_INTERVAL int = 64
+
+// DFSContext is DFS that can be cancelled: ctx is checked before the first node
+// and then every CONTEXT_CHECK_INTERVAL nodes. When ctx is done the traversal
+// stops and ctx.Err() is returned; a traversal that runs to completion or is
+// stopped by Stop returns nil.
+func DFSContext[N ChildCarrier[N], A any](
+	ctx context.Context,
+	root N,
+	accInitial A,
+	step DFSStepFunc[N, A],
+) error {
+	var err error
+	visited := 0
+	DFS(root, accInitial, func(node N, acc A) (DFSControl, A) {
+		if visited%CONTEXT_CHECK_INTERVAL == 0 {
+			if err = ctx.Err(); err != nil {
+				return Stop, acc
+			}
+		}
+		visited++
+		return step(node, acc)
+	})
+	return err
+}
@@ -0,0 +1,85 @@
+package dfs
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func newChain(length int) *exampleNode {
+	root := &exampleNode{label: "0"}
+	for node, i := root, 1; i < length; i++ {
+		child := &exampleNode{}
+		node.children = []*exampleNode{child}
+		node = child
+	}
+	return root
+}
+
+func TestDFSContext_CompletesWithLiveContext(t *testing.T) {
+	visited := 0
+	err := DFSContext(context.Background(), newChain(200), struct{}{}, func(*exampleNode, struct{}) (DFSControl, struct{}) {
+		visited++
+		return Continue, struct{}{}
+	})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if visited != 200 {
+		t.Fatalf("expected 200 visited nodes, got %d", visited)
+	}
+}
+
+func TestDFSContext_CancelledBeforeStart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	visited := 0
+	err := DFSContext(ctx, newChain(10), struct{}{}, func(*exampleNode, struct{}) (DFSControl, struct{}) {
+		visited++
+		return Continue, struct{}{}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if visited != 0 {
+		t.Fatalf("expected no visited nodes, got %d", visited)
+	}
+}
+
+func TestDFSContext_CancelledDuringTraversal(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	visited := 0
+	err := DFSContext(ctx, newChain(10*CONTEXT_CHECK_INTERVAL), struct{}{}, func(*exampleNode, struct{}) (DFSControl, struct{}) {
+		visited++
+		if visited == 10 {
+			cancel()
+		}
+		return Continue, struct{}{}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if visited != CONTEXT_CHECK_INTERVAL {
+		t.Fatalf("expected traversal to stop at the next check after %d nodes, got %d", CONTEXT_CHECK_INTERVAL, visited)
+	}
+}
+
+func TestDFSContext_StopReturnsNil(t *testing.T) {
+	visited := 0
+	err := DFSContext(context.Background(), newChain(10), struct{}{}, func(*exampleNode, struct{}) (DFSControl, struct{}) {
+		visited++
+		if visited == 3 {
+			return Stop, struct{}{}
+		}
+		return Continue, struct{}{}
+	})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if visited != 3 {
+		t.Fatalf("expected 3 visited nodes, got %d", visited)
+	}
+}
@@ -1,4 +1,4 @@
-// Package dfs provides a generic depth-first traversal helper that operates on
+// Package dfs provides generic depth-first traversal helpers that operate on
 // any node type exposing its children through the ChildCarrier interface.
 package dfs
 
@@ -8,11 +8,52 @@ type ChildCarrier[N any] interface {
 	Children() []N
 }
 
-// DFSControl tweaks traversal: set Skip to avoid visiting a node's children,
-// or Break to stop the DFS immediately.
-type DFSControl struct {
-	Skip  bool
-	Break bool
+// DFSControl tells a traversal how to proceed after a node has been processed.
+type DFSControl uint8
+
+const (
+	// Continue descends into the node's children.
+	Continue DFSControl = iota
+	// SkipChildren moves on without visiting the node's children.
+	SkipChildren
+	// Stop ends the traversal immediately.
+	Stop
+)
+
+func (c DFSControl) String() string {
+	switch c {
+	case Continue:
+		return "Continue"
+	case SkipChildren:
+		return "SkipChildren"
+	case Stop:
+		return "Stop"
+	default:
+		return "DFSControl(?)"
+	}
+}
+
+type dfsConfig struct {
+	naturalOrder bool
+}
+
+// DFSOption configures DFS and Walk.
+type DFSOption func(*dfsConfig)
+
+// WithNaturalOrder visits children in the order Children returns them instead
+// of the default reverse order, e.g. NW, NE, SW, SE for quadtree nodes.
+func WithNaturalOrder() DFSOption {
+	return func(c *dfsConfig) {
+		c.naturalOrder = true
+	}
+}
+
+func newDFSConfig(opts []DFSOption) dfsConfig {
+	var config dfsConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return config
 }
 
 // DFSStepFunc processes one node and returns traversal controls plus
@@ -22,33 +63,16 @@ type DFSStepFunc[N ChildCarrier[N], A any] func(node N, acc A) (DFSControl, A)
 // DFS walks the structure rooted at node using an explicit stack. accInitial is
 // copied into the first frame, and each returned accumulator is stored on the
 // branch that produced it, so sibling subtrees never share state unless A is a
-// reference type.
+// reference type. Children are pushed in order, so the last child is visited
+// first unless WithNaturalOrder is given. DFS reports whether a step returned
+// Stop and how many nodes were visited, including the one that stopped the
+// traversal.
 func DFS[N ChildCarrier[N], A any](
 	root N,
 	accInitial A,
 	step DFSStepFunc[N, A],
-) {
-	type frame struct {
-		node N
-		acc  A
-	}
-	stack := []frame{{root, accInitial}}
-	for len(stack) > 0 {
-		entry := stack[len(stack)-1]
-		stack = stack[:len(stack)-1]
-
-		control, nextAcc := step(entry.node, entry.acc)
-
-		if control.Skip {
-			continue
-		}
-
-		if control.Break {
-			break
-		}
-
-		for _, child := range entry.node.Children() {
-			stack = append(stack, frame{child, nextAcc})
-		}
-	}
+	opts ...DFSOption,
+) (stopped bool, visited int) {
+	t := Traverser[N, A]{config: newDFSConfig(opts)}
+	return t.run(root, accInitial, step)
 }
@@ -13,41 +13,43 @@ func (n *exampleNode) Children() []*exampleNode {
 	return n.children
 }
 
-// ExampleDFS_controlFlow documents how Skip and Break influence traversal order
+// ExampleDFS_controlFlow documents how SkipChildren and Stop influence traversal order
 // and shows that the accumulator delivered to each branch is isolated.
 func ExampleDFS_controlFlow() {
-	afterBreak := &exampleNode{label: "after-break"}
+	afterStop := &exampleNode{label: "after-stop"}
 	stop := &exampleNode{label: "stop"}
 	skipChild := &exampleNode{label: "skip-child"}
 	skip := &exampleNode{label: "skip", children: []*exampleNode{skipChild}}
 
 	root := &exampleNode{
 		label:    "root",
-		children: []*exampleNode{afterBreak, stop, skip},
+		children: []*exampleNode{afterStop, stop, skip},
 	}
 
 	var visited []string
 
-	DFS(root, nil, func(node *exampleNode, acc []string) (DFSControl, []string) {
+	stopped, count := DFS(root, nil, func(node *exampleNode, acc []string) (DFSControl, []string) {
 		nextAcc := append(append([]string(nil), acc...), node.label)
 		visited = append(visited, fmt.Sprintf("%s %v", node.label, nextAcc))
 
 		switch node.label {
 		case "skip":
-			return DFSControl{Skip: true}, nextAcc
+			return SkipChildren, nextAcc
 		case "stop":
-			return DFSControl{Break: true}, nextAcc
+			return Stop, nextAcc
 		default:
-			return DFSControl{}, nextAcc
+			return Continue, nextAcc
 		}
 	})
 
 	for _, entry := range visited {
 		fmt.Println(entry)
 	}
+	fmt.Println("stopped:", stopped, "visited:", count)
 
 	// Output:
 	// root [root]
 	// skip [root skip]
 	// stop [root stop]
+	// stopped: true visited: 3
 }
@@ -0,0 +1,196 @@
+package dfs
+
+import (
+	"slices"
+	"testing"
+)
+
+// newTestTree builds:
+//
+//	root
+//	├── a
+//	│   ├── a1
+//	│   └── a2
+//	└── b
+//	    └── b1
+func newTestTree() *exampleNode {
+	return &exampleNode{label: "root", children: []*exampleNode{
+		{label: "a", children: []*exampleNode{{label: "a1"}, {label: "a2"}}},
+		{label: "b", children: []*exampleNode{{label: "b1"}}},
+	}}
+}
+
+func TestDFS_Control(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []DFSOption
+		controls    map[string]DFSControl
+		wantOrder   []string
+		wantStopped bool
+	}{
+		{
+			name:      "continue visits children in reverse order",
+			wantOrder: []string{"root", "b", "b1", "a", "a2", "a1"},
+		},
+		{
+			name:      "natural order visits children as returned",
+			opts:      []DFSOption{WithNaturalOrder()},
+			wantOrder: []string{"root", "a", "a1", "a2", "b", "b1"},
+		},
+		{
+			name:        "natural order with skip and stop",
+			opts:        []DFSOption{WithNaturalOrder()},
+			controls:    map[string]DFSControl{"a": SkipChildren, "b1": Stop},
+			wantOrder:   []string{"root", "a", "b", "b1"},
+			wantStopped: true,
+		},
+		{
+			name:      "skip children of a branch",
+			controls:  map[string]DFSControl{"b": SkipChildren},
+			wantOrder: []string{"root", "b", "a", "a2", "a1"},
+		},
+		{
+			name:      "skip children of the root",
+			controls:  map[string]DFSControl{"root": SkipChildren},
+			wantOrder: []string{"root"},
+		},
+		{
+			name:        "stop at the root",
+			controls:    map[string]DFSControl{"root": Stop},
+			wantOrder:   []string{"root"},
+			wantStopped: true,
+		},
+		{
+			name:        "stop inside a branch leaves siblings unvisited",
+			controls:    map[string]DFSControl{"b1": Stop},
+			wantOrder:   []string{"root", "b", "b1"},
+			wantStopped: true,
+		},
+		{
+			name:        "stop after skipping another branch",
+			controls:    map[string]DFSControl{"b": SkipChildren, "a2": Stop},
+			wantOrder:   []string{"root", "b", "a", "a2"},
+			wantStopped: true,
+		},
+		{
+			name:        "stop on the last node is still reported",
+			controls:    map[string]DFSControl{"a1": Stop},
+			wantOrder:   []string{"root", "b", "b1", "a", "a2", "a1"},
+			wantStopped: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := []string{}
+			stopped, visited := DFS(newTestTree(), struct{}{}, func(node *exampleNode, _ struct{}) (DFSControl, struct{}) {
+				order = append(order, node.label)
+				return tt.controls[node.label], struct{}{}
+			}, tt.opts...)
+
+			if !slices.Equal(order, tt.wantOrder) {
+				t.Errorf("visit order = %v, expected %v", order, tt.wantOrder)
+			}
+			if stopped != tt.wantStopped {
+				t.Errorf("stopped = %v, expected %v", stopped, tt.wantStopped)
+			}
+			if visited != len(tt.wantOrder) {
+				t.Errorf("visited = %d, expected %d", visited, len(tt.wantOrder))
+			}
+		})
+	}
+}
+
+func TestWalk_Control(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []DFSOption
+		enter       map[string]DFSControl
+		leave       map[string]DFSControl
+		wantEvents  []string
+		wantStopped bool
+		wantVisited int
+	}{
+		{
+			name: "every node is entered and left",
+			wantEvents: []string{
+				"+root", "+b", "+b1", "-b1", "-b", "+a", "+a2", "-a2", "+a1", "-a1", "-a", "-root",
+			},
+			wantVisited: 6,
+		},
+		{
+			name: "natural order",
+			opts: []DFSOption{WithNaturalOrder()},
+			wantEvents: []string{
+				"+root", "+a", "+a1", "-a1", "+a2", "-a2", "-a", "+b", "+b1", "-b1", "-b", "-root",
+			},
+			wantVisited: 6,
+		},
+		{
+			name:        "skipped node is still left",
+			enter:       map[string]DFSControl{"a": SkipChildren, "b": SkipChildren},
+			wantEvents:  []string{"+root", "+b", "-b", "+a", "-a", "-root"},
+			wantVisited: 3,
+		},
+		{
+			name:        "stop on enter is not left",
+			enter:       map[string]DFSControl{"b1": Stop},
+			wantEvents:  []string{"+root", "+b", "+b1"},
+			wantStopped: true,
+			wantVisited: 3,
+		},
+		{
+			name:        "stop on leave ends the walk",
+			leave:       map[string]DFSControl{"b": Stop},
+			wantEvents:  []string{"+root", "+b", "+b1", "-b1", "-b"},
+			wantStopped: true,
+			wantVisited: 3,
+		},
+		{
+			name:        "skip returned on leave has no effect",
+			leave:       map[string]DFSControl{"b": SkipChildren},
+			wantEvents:  []string{"+root", "+b", "+b1", "-b1", "-b", "+a", "+a2", "-a2", "+a1", "-a1", "-a", "-root"},
+			wantVisited: 6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := []string{}
+			stopped, visited := Walk(newTestTree(),
+				func(node *exampleNode) DFSControl {
+					events = append(events, "+"+node.label)
+					return tt.enter[node.label]
+				},
+				func(node *exampleNode) DFSControl {
+					events = append(events, "-"+node.label)
+					return tt.leave[node.label]
+				},
+				tt.opts...,
+			)
+
+			if !slices.Equal(events, tt.wantEvents) {
+				t.Errorf("events = %v, expected %v", events, tt.wantEvents)
+			}
+			if stopped != tt.wantStopped {
+				t.Errorf("stopped = %v, expected %v", stopped, tt.wantStopped)
+			}
+			if visited != tt.wantVisited {
+				t.Errorf("visited = %d, expected %d", visited, tt.wantVisited)
+			}
+		})
+	}
+}
+
+func TestDFSControl_String(t *testing.T) {
+	for control, want := range map[DFSControl]string{
+		Continue:        "Continue",
+		SkipChildren:    "SkipChildren",
+		Stop:            "Stop",
+		DFSControl(255): "DFSControl(?)",
+	} {
+		if got := control.String(); got != want {
+			t.Errorf("%d.String() = %q, expected %q", control, got, want)
+		}
+	}
+}
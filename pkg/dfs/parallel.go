@@ -0,0 +1,268 @@
+package dfs
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// SPLIT_THRESHOLD is the default subtree size from which DFSParallel hands a
+// subtree over to the shared pool instead of walking it on the current worker.
+const SPLIT_THRESHOLD int = 1024
+
+// SizeCarrier is implemented by nodes that know the size of their subtree, e.g.
+// the number of items stored below them. DFSParallel uses it to decide which
+// subtrees are worth handing to another worker; nodes without it always are.
+type SizeCarrier interface {
+	Size() int
+}
+
+// ParallelStepFunc processes one node and folds it into the result of the
+// worker visiting it.
+type ParallelStepFunc[N ChildCarrier[N], R any] func(node N, result R) (DFSControl, R)
+
+// ReduceFunc merges the results of two workers.
+type ReduceFunc[R any] func(a, b R) R
+
+type parallelConfig struct {
+	workers   int
+	threshold int
+}
+
+type ParallelOption func(*parallelConfig)
+
+// WithWorkers bounds the number of goroutines used by DFSParallel; the default
+// is runtime.GOMAXPROCS(0).
+func WithWorkers(workers int) ParallelOption {
+	return func(c *parallelConfig) {
+		if workers > 0 {
+			c.workers = workers
+		}
+	}
+}
+
+// WithSplitThreshold sets the subtree size from which a subtree is handed to
+// the shared pool; the default is SPLIT_THRESHOLD.
+func WithSplitThreshold(threshold int) ParallelOption {
+	return func(c *parallelConfig) {
+		if threshold > 0 {
+			c.threshold = threshold
+		}
+	}
+}
+
+// DFSParallel visits every node reachable from root using a bounded pool of
+// workers. Each worker walks subtrees depth-first with its own stack and folds
+// the visited nodes into a private result that starts as identity; subtrees at
+// least as large as the split threshold are pushed onto the worker's deque,
+// where idle workers steal them from. Once the traversal finishes the worker
+// results are merged with reduce.
+//
+// The visiting order is unspecified, so step must not depend on it.
+// SkipChildren avoids a node's children and Stop halts all workers as soon as
+// they notice it.
+// identity is copied into every worker, so it should not share mutable state.
+// step must be safe for concurrent use on distinct nodes.
+func DFSParallel[N ChildCarrier[N], R any](
+	root N,
+	identity R,
+	step ParallelStepFunc[N, R],
+	reduce ReduceFunc[R],
+	opts ...ParallelOption,
+) R {
+	config := parallelConfig{workers: runtime.GOMAXPROCS(0), threshold: SPLIT_THRESHOLD}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	if config.workers == 1 || !splittable(root, config.threshold) {
+		result := identity
+		DFS(root, struct{}{}, func(node N, _ struct{}) (DFSControl, struct{}) {
+			var control DFSControl
+			control, result = step(node, result)
+			return control, struct{}{}
+		})
+		return result
+	}
+
+	pool := newWorkPool[N](config.workers)
+	pool.push(0, root)
+
+	results := make([]R, config.workers)
+	var wg sync.WaitGroup
+	for id := range config.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[id] = runWorker(pool, id, identity, step, config.threshold)
+		}()
+	}
+	wg.Wait()
+
+	result := results[0]
+	for _, r := range results[1:] {
+		result = reduce(result, r)
+	}
+	return result
+}
+
+func splittable[N any](node N, threshold int) bool {
+	if sized, ok := any(node).(SizeCarrier); ok {
+		return sized.Size() >= threshold
+	}
+	return true
+}
+
+// workPool holds one deque of pending subtrees per worker. Owners push and pop
+// at the back of their deque, thieves take from the front, so stolen subtrees
+// tend to be the large ones near the root. Workers finding no subtree park on
+// idle until one is pushed or the traversal ends.
+type workPool[N any] struct {
+	deques  []deque[N]
+	pending atomic.Int64
+	stopped atomic.Bool
+	mu      sync.Mutex
+	idle    *sync.Cond
+	pushes  uint64
+}
+
+type deque[N any] struct {
+	mu    sync.Mutex
+	nodes []N
+}
+
+func newWorkPool[N any](workers int) *workPool[N] {
+	p := &workPool[N]{deques: make([]deque[N], workers)}
+	p.idle = sync.NewCond(&p.mu)
+	return p
+}
+
+func (p *workPool[N]) push(worker int, node N) {
+	p.pending.Add(1)
+	d := &p.deques[worker]
+	d.mu.Lock()
+	d.nodes = append(d.nodes, node)
+	d.mu.Unlock()
+
+	p.mu.Lock()
+	p.pushes++
+	p.mu.Unlock()
+	p.idle.Signal()
+}
+
+// generation returns the number of pushes so far; a worker reads it before
+// looking for a subtree and passes it to wait when it finds none.
+func (p *workPool[N]) generation() uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.pushes
+}
+
+// wait parks the calling worker until a subtree is pushed after generation
+// seen, or until no subtree is pending or the traversal is stopped. It
+// reports whether the worker should look for work again.
+func (p *workPool[N]) wait(seen uint64) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for p.pushes == seen && p.pending.Load() > 0 && !p.stopped.Load() {
+		p.idle.Wait()
+	}
+	return p.pending.Load() > 0 && !p.stopped.Load()
+}
+
+// wake releases every parked worker once the traversal has ended.
+func (p *workPool[N]) wake() {
+	p.mu.Lock()
+	p.idle.Broadcast()
+	p.mu.Unlock()
+}
+
+func (p *workPool[N]) stop() {
+	p.stopped.Store(true)
+	p.wake()
+}
+
+func (p *workPool[N]) done() {
+	if p.pending.Add(-1) == 0 {
+		p.wake()
+	}
+}
+
+// next returns a subtree for worker: its own most recent one, or one stolen
+// from the front of another worker's deque.
+func (p *workPool[N]) next(worker int) (N, bool) {
+	d := &p.deques[worker]
+	d.mu.Lock()
+	if n := len(d.nodes); n > 0 {
+		node := d.nodes[n-1]
+		d.nodes = d.nodes[:n-1]
+		d.mu.Unlock()
+		return node, true
+	}
+	d.mu.Unlock()
+
+	for i := 1; i < len(p.deques); i++ {
+		victim := &p.deques[(worker+i)%len(p.deques)]
+		victim.mu.Lock()
+		if len(victim.nodes) > 0 {
+			node := victim.nodes[0]
+			victim.nodes = victim.nodes[1:]
+			victim.mu.Unlock()
+			return node, true
+		}
+		victim.mu.Unlock()
+	}
+
+	var zero N
+	return zero, false
+}
+
+// runWorker processes subtrees from the pool until none are pending anywhere or
+// the traversal is stopped, and returns the worker's result.
+func runWorker[N ChildCarrier[N], R any](
+	p *workPool[N],
+	worker int,
+	result R,
+	step ParallelStepFunc[N, R],
+	threshold int,
+) R {
+	stack := []N{}
+	for !p.stopped.Load() {
+		seen := p.generation()
+		subtree, ok := p.next(worker)
+		if !ok {
+			if !p.wait(seen) {
+				break
+			}
+			continue
+		}
+
+		stack = append(stack[:0], subtree)
+		for len(stack) > 0 && !p.stopped.Load() {
+			node := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			var control DFSControl
+			control, result = step(node, result)
+
+			if control == Stop {
+				p.stop()
+				break
+			}
+
+			if control == SkipChildren {
+				continue
+			}
+
+			for _, child := range node.Children() {
+				if splittable(child, threshold) {
+					p.push(worker, child)
+				} else {
+					stack = append(stack, child)
+				}
+			}
+		}
+		p.done()
+	}
+	return result
+}
@@ -0,0 +1,114 @@
+package dfs
+
+import (
+	"slices"
+	"testing"
+)
+
+type sizedNode struct {
+	value    int
+	size     int
+	children []*sizedNode
+}
+
+func (n *sizedNode) Children() []*sizedNode { return n.children }
+func (n *sizedNode) Size() int              { return n.size }
+
+// newSizedTree builds a complete tree with the given fan-out and depth whose
+// nodes are numbered from next.
+func newSizedTree(fanOut, depth int, next *int) *sizedNode {
+	node := &sizedNode{value: *next, size: 1}
+	*next++
+	if depth == 0 {
+		return node
+	}
+	for range fanOut {
+		child := newSizedTree(fanOut, depth-1, next)
+		node.size += child.size
+		node.children = append(node.children, child)
+	}
+	return node
+}
+
+func collectValues(node *sizedNode, values []int) (DFSControl, []int) {
+	return Continue, append(values, node.value)
+}
+
+func concat(a, b []int) []int { return append(a, b...) }
+
+func TestDFSParallel_VisitsEveryNodeOnce(t *testing.T) {
+	next := 0
+	root := newSizedTree(4, 6, &next)
+
+	for _, opts := range [][]ParallelOption{
+		nil,
+		{WithWorkers(1)},
+		{WithWorkers(8), WithSplitThreshold(1)},
+		{WithWorkers(3), WithSplitThreshold(50)},
+		{WithSplitThreshold(root.size + 1)},
+	} {
+		values := DFSParallel(root, nil, collectValues, concat, opts...)
+		slices.Sort(values)
+		if len(values) != next {
+			t.Fatalf("expected %d visited nodes, got %d", next, len(values))
+		}
+		for i, v := range values {
+			if v != i {
+				t.Fatalf("expected every node exactly once, found node %d at position %d", v, i)
+			}
+		}
+	}
+}
+
+func TestDFSParallel_SkipAvoidsChildren(t *testing.T) {
+	next := 0
+	root := newSizedTree(4, 5, &next)
+
+	count := DFSParallel(root, 0,
+		func(node *sizedNode, count int) (DFSControl, int) {
+			if node != root {
+				return SkipChildren, count + 1
+			}
+			return Continue, count + 1
+		},
+		func(a, b int) int { return a + b },
+		WithWorkers(4), WithSplitThreshold(1),
+	)
+	if count != 5 {
+		t.Fatalf("expected the root and its 4 children, got %d nodes", count)
+	}
+}
+
+func TestDFSParallel_StopHaltsWorkers(t *testing.T) {
+	next := 0
+	root := newSizedTree(4, 6, &next)
+
+	count := DFSParallel(root, 0,
+		func(node *sizedNode, count int) (DFSControl, int) {
+			return Stop, count + 1
+		},
+		func(a, b int) int { return a + b },
+		WithWorkers(4), WithSplitThreshold(1),
+	)
+	if count != 1 {
+		t.Fatalf("expected traversal to stop at the root, got %d nodes", count)
+	}
+}
+
+func BenchmarkDFSParallel(b *testing.B) {
+	next := 0
+	root := newSizedTree(4, 8, &next)
+	sum := func(node *sizedNode, total int) (DFSControl, int) { return Continue, total + node.value }
+	add := func(a, b int) int { return a + b }
+
+	b.Run("sequential", func(b *testing.B) {
+		for b.Loop() {
+			DFSParallel(root, 0, sum, add, WithWorkers(1))
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		for b.Loop() {
+			DFSParallel(root, 0, sum, add)
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package dfs
+
+import "sync"
+
+type frame[N, A any] struct {
+	node N
+	acc  A
+}
+
+// Traverser runs DFS traversals while keeping its stack between calls, so a
+// warmed traverser does not allocate. A Traverser is not safe for concurrent
+// use; share one through a TraverserPool instead.
+type Traverser[N ChildCarrier[N], A any] struct {
+	stack  []frame[N, A]
+	config dfsConfig
+}
+
+// NewTraverser returns a Traverser applying opts to every traversal.
+func NewTraverser[N ChildCarrier[N], A any](opts ...DFSOption) *Traverser[N, A] {
+	return &Traverser[N, A]{config: newDFSConfig(opts)}
+}
+
+// DFS behaves like the package-level DFS but reuses the traverser's stack. The
+// stack is cleared afterwards, so it does not keep visited nodes reachable.
+func (t *Traverser[N, A]) DFS(root N, accInitial A, step DFSStepFunc[N, A]) (stopped bool, visited int) {
+	stopped, visited = t.run(root, accInitial, step)
+	clear(t.stack[:cap(t.stack)])
+	t.stack = t.stack[:0]
+	return stopped, visited
+}
+
+// run performs the traversal and leaves the grown stack on the traverser.
+func (t *Traverser[N, A]) run(root N, accInitial A, step DFSStepFunc[N, A]) (stopped bool, visited int) {
+	stack := append(t.stack[:0], frame[N, A]{root, accInitial})
+	for len(stack) > 0 {
+		entry := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		control, nextAcc := step(entry.node, entry.acc)
+		visited++
+
+		if control == Stop {
+			stopped = true
+			break
+		}
+		if control == SkipChildren {
+			continue
+		}
+
+		children := entry.node.Children()
+		for i := range children {
+			if t.config.naturalOrder {
+				i = len(children) - 1 - i
+			}
+			stack = append(stack, frame[N, A]{children[i], nextAcc})
+		}
+	}
+	t.stack = stack[:0]
+	return stopped, visited
+}
+
+// TraverserPool hands out Traversers backed by a sync.Pool, for code that
+// traverses from several goroutines. The zero value is not usable; a nil pool
+// falls back to the package-level DFS.
+type TraverserPool[N ChildCarrier[N], A any] struct {
+	pool sync.Pool
+}
+
+// NewTraverserPool returns a pool of Traversers applying opts.
+func NewTraverserPool[N ChildCarrier[N], A any](opts ...DFSOption) *TraverserPool[N, A] {
+	config := newDFSConfig(opts)
+	return &TraverserPool[N, A]{pool: sync.Pool{
+		New: func() any { return &Traverser[N, A]{config: config} },
+	}}
+}
+
+// Get takes a Traverser from the pool; return it with Put once done.
+func (p *TraverserPool[N, A]) Get() *Traverser[N, A] {
+	return p.pool.Get().(*Traverser[N, A])
+}
+
+// Put returns t to the pool.
+func (p *TraverserPool[N, A]) Put(t *Traverser[N, A]) {
+	p.pool.Put(t)
+}
+
+// DFS runs a traversal on a pooled Traverser.
+func (p *TraverserPool[N, A]) DFS(root N, accInitial A, step DFSStepFunc[N, A]) (stopped bool, visited int) {
+	if p == nil {
+		return DFS(root, accInitial, step)
+	}
+	t := p.Get()
+	defer p.Put(t)
+	return t.DFS(root, accInitial, step)
+}
@@ -0,0 +1,123 @@
+package dfs
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+func countNodes(_ *sizedNode, count int) (DFSControl, int) {
+	return Continue, count + 1
+}
+
+func TestTraverser_MatchesDFS(t *testing.T) {
+	traverser := NewTraverser[*exampleNode, struct{}](WithNaturalOrder())
+	for range 3 {
+		want, got := []string{}, []string{}
+		DFS(newTestTree(), struct{}{}, func(node *exampleNode, _ struct{}) (DFSControl, struct{}) {
+			want = append(want, node.label)
+			return Continue, struct{}{}
+		}, WithNaturalOrder())
+		stopped, visited := traverser.DFS(newTestTree(), struct{}{}, func(node *exampleNode, _ struct{}) (DFSControl, struct{}) {
+			got = append(got, node.label)
+			return Continue, struct{}{}
+		})
+		if !slices.Equal(got, want) || stopped || visited != len(want) {
+			t.Fatalf("traverser visited %v (stopped %v, %d nodes), expected %v", got, stopped, visited, want)
+		}
+	}
+}
+
+func TestTraverser_ReleasesNodesAfterStop(t *testing.T) {
+	traverser := NewTraverser[*exampleNode, struct{}]()
+	stopped, _ := traverser.DFS(newTestTree(), struct{}{}, func(node *exampleNode, _ struct{}) (DFSControl, struct{}) {
+		if node.label == "b" {
+			return Stop, struct{}{}
+		}
+		return Continue, struct{}{}
+	})
+	if !stopped {
+		t.Fatal("expected the traversal to stop")
+	}
+	for i, f := range traverser.stack[:cap(traverser.stack)] {
+		if f.node != nil {
+			t.Errorf("stack slot %d still references %s", i, f.node.label)
+		}
+	}
+}
+
+func TestTraverser_ZeroAllocationsWhenWarm(t *testing.T) {
+	next := 0
+	root := newSizedTree(4, 5, &next)
+	traverser := NewTraverser[*sizedNode, int]()
+	traverser.DFS(root, 0, countNodes)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		traverser.DFS(root, 0, countNodes)
+	})
+	if allocs != 0 {
+		t.Errorf("expected no allocations per traversal, got %v", allocs)
+	}
+}
+
+func TestTraverserPool_ConcurrentUse(t *testing.T) {
+	next := 0
+	root := newSizedTree(4, 5, &next)
+	pool := NewTraverserPool[*sizedNode, int]()
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				total := 0
+				pool.DFS(root, 0, func(_ *sizedNode, depth int) (DFSControl, int) {
+					total++
+					return Continue, depth + 1
+				})
+				if total != next {
+					t.Errorf("expected %d visited nodes, got %d", next, total)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestTraverserPool_NilFallsBackToDFS(t *testing.T) {
+	var pool *TraverserPool[*exampleNode, struct{}]
+	_, visited := pool.DFS(newTestTree(), struct{}{}, func(*exampleNode, struct{}) (DFSControl, struct{}) {
+		return Continue, struct{}{}
+	})
+	if visited != 6 {
+		t.Errorf("expected 6 visited nodes, got %d", visited)
+	}
+}
+
+func BenchmarkTraversal(b *testing.B) {
+	next := 0
+	root := newSizedTree(4, 6, &next)
+
+	b.Run("DFS", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			DFS(root, 0, countNodes)
+		}
+	})
+	b.Run("Traverser", func(b *testing.B) {
+		traverser := NewTraverser[*sizedNode, int]()
+		b.ReportAllocs()
+		for b.Loop() {
+			traverser.DFS(root, 0, countNodes)
+		}
+	})
+	b.Run("TraverserPool", func(b *testing.B) {
+		pool := NewTraverserPool[*sizedNode, int]()
+		b.ReportAllocs()
+		for b.Loop() {
+			pool.DFS(root, 0, countNodes)
+		}
+	})
+}
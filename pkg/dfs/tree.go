@@ -0,0 +1,81 @@
+package dfs
+
+// The helpers below visit nodes depth-first in natural order: a node comes
+// before its children, and children in the order Children returns them.
+
+// Find returns the first node satisfying pred.
+func Find[N ChildCarrier[N]](root N, pred func(N) bool) (N, bool) {
+	var found N
+	stopped, _ := DFS(root, struct{}{}, func(node N, _ struct{}) (DFSControl, struct{}) {
+		if pred(node) {
+			found = node
+			return Stop, struct{}{}
+		}
+		return Continue, struct{}{}
+	}, WithNaturalOrder())
+	return found, stopped
+}
+
+// Collect returns every node satisfying pred.
+func Collect[N ChildCarrier[N]](root N, pred func(N) bool) []N {
+	return Fold(root, []N{}, func(nodes []N, node N) []N {
+		if pred(node) {
+			nodes = append(nodes, node)
+		}
+		return nodes
+	})
+}
+
+// Count returns the number of nodes satisfying pred, or of all nodes when pred
+// is nil.
+func Count[N ChildCarrier[N]](root N, pred func(N) bool) int {
+	return Fold(root, 0, func(count int, node N) int {
+		if pred == nil || pred(node) {
+			count++
+		}
+		return count
+	})
+}
+
+// MaxDepth returns the number of levels below and including root, so a lone
+// root has depth 1.
+func MaxDepth[N ChildCarrier[N]](root N) int {
+	maxDepth := 0
+	DFS(root, 0, func(_ N, depth int) (DFSControl, int) {
+		depth++
+		maxDepth = max(maxDepth, depth)
+		return Continue, depth
+	})
+	return maxDepth
+}
+
+// Paths returns the path from root to every node satisfying pred, both ends
+// included.
+func Paths[N ChildCarrier[N]](root N, pred func(N) bool) [][]N {
+	paths := [][]N{}
+	DFS(root, []N(nil), func(node N, path []N) (DFSControl, []N) {
+		// Cap the capacity so siblings appending to the shared prefix never
+		// overwrite each other's last element.
+		path = append(path[:len(path):len(path)], node)
+		if pred(node) {
+			paths = append(paths, path)
+		}
+		return Continue, path
+	}, WithNaturalOrder())
+	return paths
+}
+
+// Leaves returns the nodes without children.
+func Leaves[N ChildCarrier[N]](root N) []N {
+	return Collect(root, func(node N) bool { return len(node.Children()) == 0 })
+}
+
+// Fold combines every node into an accumulator, starting from init.
+func Fold[N ChildCarrier[N], A any](root N, init A, fn func(acc A, node N) A) A {
+	acc := init
+	DFS(root, struct{}{}, func(node N, _ struct{}) (DFSControl, struct{}) {
+		acc = fn(acc, node)
+		return Continue, struct{}{}
+	}, WithNaturalOrder())
+	return acc
+}
@@ -0,0 +1,109 @@
+package dfs
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func labels(nodes []*exampleNode) []string {
+	out := make([]string, len(nodes))
+	for i, node := range nodes {
+		out[i] = node.label
+	}
+	return out
+}
+
+func hasLabel(labels ...string) func(*exampleNode) bool {
+	return func(node *exampleNode) bool { return slices.Contains(labels, node.label) }
+}
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		name      string
+		pred      func(*exampleNode) bool
+		wantLabel string
+		wantFound bool
+	}{
+		{"root", hasLabel("root"), "root", true},
+		{"first match in natural order", hasLabel("a2", "b1"), "a2", true},
+		{"no match", hasLabel("z"), "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, found := Find(newTestTree(), tt.pred)
+			if found != tt.wantFound {
+				t.Fatalf("found = %v, expected %v", found, tt.wantFound)
+			}
+			if found && node.label != tt.wantLabel {
+				t.Errorf("found %s, expected %s", node.label, tt.wantLabel)
+			}
+			if !found && node != nil {
+				t.Errorf("expected zero node when nothing matches, got %v", node)
+			}
+		})
+	}
+}
+
+func TestCollect(t *testing.T) {
+	got := labels(Collect(newTestTree(), func(node *exampleNode) bool { return len(node.label) == 2 }))
+	if want := []string{"a1", "a2", "b1"}; !slices.Equal(got, want) {
+		t.Errorf("Collect = %v, expected %v", got, want)
+	}
+	if got := Collect(newTestTree(), hasLabel("z")); len(got) != 0 {
+		t.Errorf("expected no nodes, got %v", labels(got))
+	}
+}
+
+func TestCount(t *testing.T) {
+	if got := Count(newTestTree(), nil); got != 6 {
+		t.Errorf("Count(nil) = %d, expected 6", got)
+	}
+	if got := Count(newTestTree(), hasLabel("a", "b", "z")); got != 2 {
+		t.Errorf("Count(a, b, z) = %d, expected 2", got)
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	if got := MaxDepth(&exampleNode{label: "lone"}); got != 1 {
+		t.Errorf("MaxDepth(lone root) = %d, expected 1", got)
+	}
+	if got := MaxDepth(newTestTree()); got != 3 {
+		t.Errorf("MaxDepth = %d, expected 3", got)
+	}
+	if got := MaxDepth(newChain(50)); got != 50 {
+		t.Errorf("MaxDepth(chain) = %d, expected 50", got)
+	}
+}
+
+func TestPaths(t *testing.T) {
+	paths := Paths(newTestTree(), func(node *exampleNode) bool { return len(node.children) == 0 })
+	got := make([]string, len(paths))
+	for i, path := range paths {
+		got[i] = fmt.Sprint(labels(path))
+	}
+	want := []string{"[root a a1]", "[root a a2]", "[root b b1]"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Paths = %v, expected %v", got, want)
+	}
+
+	if paths := Paths(newTestTree(), hasLabel("root")); len(paths) != 1 || len(paths[0]) != 1 {
+		t.Errorf("expected the root path alone, got %v", paths)
+	}
+}
+
+func TestLeaves(t *testing.T) {
+	got := labels(Leaves(newTestTree()))
+	if want := []string{"a1", "a2", "b1"}; !slices.Equal(got, want) {
+		t.Errorf("Leaves = %v, expected %v", got, want)
+	}
+}
+
+func TestFold(t *testing.T) {
+	got := Fold(newTestTree(), "", func(acc string, node *exampleNode) string {
+		return acc + "/" + node.label
+	})
+	if want := "/root/a/a1/a2/b/b1"; got != want {
+		t.Errorf("Fold = %q, expected %q", got, want)
+	}
+}
@@ -0,0 +1,64 @@
+package dfs
+
+// VisitFunc processes one node during Walk or DFSPostOrder. Stop ends the
+// traversal immediately; SkipChildren avoids the node's children and is only
+// meaningful when entering a node.
+type VisitFunc[N any] func(node N) DFSControl
+
+// Walk visits the structure rooted at root depth-first, calling enter when a
+// node is reached and leave once all of its children have been left, so leave
+// sees children before their parents. Either hook may be nil. A node skipped
+// on enter is still left, without visiting its children. Siblings are visited
+// in the same order as DFS, using an explicit stack instead of recursion, and
+// accept the same options. Walk reports whether a hook returned Stop and how
+// many nodes were entered.
+func Walk[N ChildCarrier[N]](root N, enter, leave VisitFunc[N], opts ...DFSOption) (stopped bool, visited int) {
+	type frame struct {
+		node    N
+		entered bool
+	}
+	config := newDFSConfig(opts)
+	stack := []frame{{node: root}}
+	for len(stack) > 0 {
+		entry := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if entry.entered {
+			if leave != nil && leave(entry.node) == Stop {
+				return true, visited
+			}
+			continue
+		}
+
+		control := Continue
+		if enter != nil {
+			control = enter(entry.node)
+		}
+		visited++
+
+		if control == Stop {
+			return true, visited
+		}
+
+		stack = append(stack, frame{node: entry.node, entered: true})
+
+		if control == SkipChildren {
+			continue
+		}
+
+		children := entry.node.Children()
+		for i := range children {
+			if config.naturalOrder {
+				i = len(children) - 1 - i
+			}
+			stack = append(stack, frame{node: children[i]})
+		}
+	}
+	return false, visited
+}
+
+// DFSPostOrder visits the structure rooted at root so that every node comes
+// after all of its descendants, e.g. to compress or aggregate bottom-up.
+func DFSPostOrder[N ChildCarrier[N]](root N, visit VisitFunc[N], opts ...DFSOption) (stopped bool, visited int) {
+	return Walk(root, nil, visit, opts...)
+}
@@ -0,0 +1,92 @@
+package dfs
+
+import (
+	"fmt"
+	"strings"
+)
+
+func exampleTree() *exampleNode {
+	return &exampleNode{label: "root", children: []*exampleNode{
+		{label: "a", children: []*exampleNode{{label: "a1"}, {label: "a2"}}},
+		{label: "b"},
+	}}
+}
+
+// ExampleWalk prints the tree with enter and leave hooks; leave runs once the
+// whole subtree of a node has been visited.
+func ExampleWalk() {
+	depth := 0
+	Walk(exampleTree(),
+		func(node *exampleNode) DFSControl {
+			fmt.Printf("%s<%s>\n", strings.Repeat("  ", depth), node.label)
+			depth++
+			return Continue
+		},
+		func(node *exampleNode) DFSControl {
+			depth--
+			fmt.Printf("%s</%s>\n", strings.Repeat("  ", depth), node.label)
+			return Continue
+		},
+	)
+
+	// Output:
+	// <root>
+	//   <b>
+	//   </b>
+	//   <a>
+	//     <a2>
+	//     </a2>
+	//     <a1>
+	//     </a1>
+	//   </a>
+	// </root>
+}
+
+// ExampleWalk_skip shows that a node skipped on enter is still left, while
+// its children are never visited.
+func ExampleWalk_skip() {
+	Walk(exampleTree(),
+		func(node *exampleNode) DFSControl {
+			fmt.Println("enter", node.label)
+			if node.label == "a" {
+				return SkipChildren
+			}
+			return Continue
+		},
+		func(node *exampleNode) DFSControl {
+			fmt.Println("leave", node.label)
+			if node.label == "a" {
+				return Stop
+			}
+			return Continue
+		},
+	)
+
+	// Output:
+	// enter root
+	// enter b
+	// leave b
+	// enter a
+	// leave a
+}
+
+// ExampleDFSPostOrder sums subtree sizes bottom-up: each node is visited after
+// all of its descendants.
+func ExampleDFSPostOrder() {
+	sizes := map[*exampleNode]int{}
+	DFSPostOrder(exampleTree(), func(node *exampleNode) DFSControl {
+		sizes[node] = 1
+		for _, child := range node.children {
+			sizes[node] += sizes[child]
+		}
+		fmt.Println(node.label, sizes[node])
+		return Continue
+	})
+
+	// Output:
+	// b 1
+	// a2 1
+	// a1 1
+	// a 3
+	// root 5
+}
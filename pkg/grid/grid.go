@@ -0,0 +1,217 @@
+// Package grid provides a uniform spatial hash grid with the same
+// Add/Remove/FindNeighbors/BatchUpdate surface as qtree.QuadTree. For dense
+// entities of similar size a grid of fixed cells is often cheaper than a tree.
+package grid
+
+import (
+	"math"
+	"slices"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokg/pkg/plane"
+	"github.com/kjkrol/gokq/pkg/qtree"
+)
+
+// Grid divides the plane viewport into square cells of a user-chosen size and
+// registers every item in each cell its bounds overlap.
+type Grid[T geom.Numeric] struct {
+	space    plane.Space2D[T]
+	viewport geom.AABB[T]
+	cellSize T
+	cols     int
+	rows     int
+	cells    [][]qtree.Item[T]
+	count    int
+}
+
+var _ qtree.SpatialIndex[float64] = (*Grid[float64])(nil)
+
+// cellRange is an inclusive range of cell columns and rows.
+type cellRange struct {
+	x0, y0, x1, y1 int
+}
+
+// NewGrid builds a Grid covering the supplied plane viewport with cells of
+// cellSize plane units; cellSize is best set close to the typical item size.
+func NewGrid[T geom.Numeric](plane plane.Space2D[T], cellSize T) *Grid[T] {
+	viewport := plane.Viewport()
+	if cellSize <= 0 {
+		cellSize = max(viewport.BottomRight.X-viewport.TopLeft.X, viewport.BottomRight.Y-viewport.TopLeft.Y, 1)
+	}
+	cells := func(lo, hi T) int {
+		return max(int(math.Ceil((float64(hi)-float64(lo))/float64(cellSize))), 1)
+	}
+	g := &Grid[T]{
+		space:    plane,
+		viewport: viewport,
+		cellSize: cellSize,
+		cols:     cells(viewport.TopLeft.X, viewport.BottomRight.X),
+		rows:     cells(viewport.TopLeft.Y, viewport.BottomRight.Y),
+	}
+	g.cells = make([][]qtree.Item[T], g.cols*g.rows)
+	return g
+}
+
+// Add inserts item into the grid; returns false if it lies outside the viewport.
+func (g *Grid[T]) Add(item qtree.Item[T]) bool {
+	box := item.Bound()
+	if !g.viewport.Contains(box) {
+		return false
+	}
+	g.visitCells(g.rangeOf(box), func(cell int) {
+		g.cells[cell] = append(g.cells[cell], item)
+	})
+	g.count++
+	return true
+}
+
+// Remove deletes item from the grid; returns false when nothing was removed.
+func (g *Grid[T]) Remove(item qtree.Item[T]) bool {
+	box := item.Bound()
+	if !g.viewport.Contains(box) {
+		return false
+	}
+	removed := false
+	g.visitCells(g.rangeOf(box), func(cell int) {
+		if i := slices.Index(g.cells[cell], item); i >= 0 {
+			g.cells[cell] = slices.Delete(g.cells[cell], i, i+1)
+			removed = true
+		}
+	})
+	if removed {
+		g.count--
+	}
+	return removed
+}
+
+// Close releases internal resources held by the grid.
+func (g *Grid[T]) Close() {
+	g.cells = nil
+	g.count = 0
+}
+
+// Count returns the number of items stored in the grid.
+func (g *Grid[T]) Count() int {
+	return g.count
+}
+
+// AllItems returns a snapshot of every stored item, ordered as qtree.SortItems.
+func (g *Grid[T]) AllItems() []qtree.Item[T] {
+	items := make([]qtree.Item[T], 0, g.count)
+	for i, cell := range g.cells {
+		for _, item := range cell {
+			if g.homeCell(item) == i {
+				items = append(items, item)
+			}
+		}
+	}
+	qtree.SortItems(items)
+	return items
+}
+
+// FindNeighbors retrieves items within margin of the target's bounds. On cyclic
+// planes the search wraps around the edges, visiting the cells on the opposite
+// side of the viewport.
+func (g *Grid[T]) FindNeighbors(target qtree.Item[T], margin T) []qtree.Item[T] {
+	probe := g.space.WrapAABB(target.Bound())
+	g.space.Expand(&probe, margin)
+	fragments := []geom.AABB[T]{probe.AABB}
+	probe.VisitFragments(func(_ plane.FragPosition, aabb geom.AABB[T]) bool {
+		fragments = append(fragments, aabb)
+		return true
+	})
+
+	boundingBoxDistance := g.space.AABBDistance()
+	seen := make(map[qtree.Item[T]]struct{})
+	neighbors := make([]qtree.Item[T], 0)
+	for _, fragment := range fragments {
+		if !g.viewport.Intersects(fragment) {
+			continue
+		}
+		g.visitCells(g.rangeOf(fragment), func(cell int) {
+			for _, item := range g.cells[cell] {
+				if _, ok := seen[item]; ok {
+					continue
+				}
+				seen[item] = struct{}{}
+				if item.SameID(target) {
+					continue
+				}
+				if boundingBoxDistance(target.Bound(), item.Bound()) <= margin {
+					neighbors = append(neighbors, item)
+				}
+			}
+		})
+	}
+
+	qtree.SortItems(neighbors)
+	return neighbors
+}
+
+// BatchUpdate removes a batch of items and inserts the replacements. Setting
+// triggerCompression also trims the storage of the cells the batch emptied out.
+func (g *Grid[T]) BatchUpdate(toRemove []qtree.Item[T], toAdd []qtree.Item[T], triggerCompression bool) {
+	if g == nil || g.cells == nil {
+		return
+	}
+
+	touched := make(map[int]struct{})
+	for _, item := range toRemove {
+		if !g.viewport.Contains(item.Bound()) {
+			continue
+		}
+		removed := false
+		g.visitCells(g.rangeOf(item.Bound()), func(cell int) {
+			if i := slices.IndexFunc(g.cells[cell], func(it qtree.Item[T]) bool { return qtree.SameItem(it, item) }); i >= 0 {
+				g.cells[cell] = slices.Delete(g.cells[cell], i, i+1)
+				touched[cell] = struct{}{}
+				removed = true
+			}
+		})
+		if removed {
+			g.count--
+		}
+	}
+
+	for _, item := range toAdd {
+		g.Add(item)
+	}
+
+	if triggerCompression {
+		for cell := range touched {
+			g.cells[cell] = slices.Clip(g.cells[cell])
+			if len(g.cells[cell]) == 0 {
+				g.cells[cell] = nil
+			}
+		}
+	}
+}
+
+// rangeOf returns the cells overlapped by box, which must intersect the viewport.
+func (g *Grid[T]) rangeOf(box geom.AABB[T]) cellRange {
+	x0, x1 := g.span(box.TopLeft.X, box.BottomRight.X, g.viewport.TopLeft.X, g.cols)
+	y0, y1 := g.span(box.TopLeft.Y, box.BottomRight.Y, g.viewport.TopLeft.Y, g.rows)
+	return cellRange{x0, y0, x1, y1}
+}
+
+func (g *Grid[T]) span(lo, hi, origin T, cells int) (int, int) {
+	size := float64(g.cellSize)
+	c0 := int(math.Floor((float64(lo) - float64(origin)) / size))
+	c1 := int(math.Floor((float64(hi) - float64(origin)) / size))
+	return min(max(c0, 0), cells-1), min(max(c1, 0), cells-1)
+}
+
+func (g *Grid[T]) visitCells(r cellRange, fn func(cell int)) {
+	for y := r.y0; y <= r.y1; y++ {
+		for x := r.x0; x <= r.x1; x++ {
+			fn(y*g.cols + x)
+		}
+	}
+}
+
+// homeCell returns the first cell item is registered in, so items spanning
+// several cells are reported once.
+func (g *Grid[T]) homeCell(item qtree.Item[T]) int {
+	r := g.rangeOf(item.Bound())
+	return r.y0*g.cols + r.x0
+}
@@ -0,0 +1,153 @@
+package grid
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokg/pkg/plane"
+	"github.com/kjkrol/gokq/pkg/qtree"
+	"github.com/kjkrol/gokq/pkg/qtree/qtreetest"
+	"github.com/kjkrol/goku/pkg/sliceutils"
+)
+
+type sprite struct {
+	geom.AABB[float64]
+	id int
+}
+
+func (s *sprite) Bound() geom.AABB[float64] { return s.AABB }
+func (s *sprite) SameID(other qtree.Item[float64]) bool {
+	o, ok := other.(*sprite)
+	return ok && o.id == s.id
+}
+func (s *sprite) String() string { return fmt.Sprintf("#%d", s.id) }
+
+func newSprites(rnd *rand.Rand, n int, extent, size float64) []qtree.Item[float64] {
+	sprites := make([]qtree.Item[float64], n)
+	for i := range sprites {
+		w, h := 1+rnd.Float64()*size, 1+rnd.Float64()*size
+		pos := geom.NewVec(rnd.Float64()*(extent-w), rnd.Float64()*(extent-h))
+		sprites[i] = &sprite{AABB: geom.NewAABBAt(pos, w, h), id: i}
+	}
+	return sprites
+}
+
+func TestGrid_MatchesQuadTree(t *testing.T) {
+	for _, space := range []plane.Space2D[float64]{
+		plane.NewEuclidean2D(128.0, 128.0),
+		plane.NewToroidal2D(128.0, 128.0),
+	} {
+		t.Run(space.Name(), func(t *testing.T) {
+			grid := NewGrid(space, 10.0)
+			defer grid.Close()
+			tree := qtree.NewQuadTree(space)
+			defer tree.Close()
+
+			sprites := newSprites(rand.New(rand.NewSource(36)), 600, 128, 12)
+			for _, s := range sprites {
+				if !grid.Add(s) || !tree.Add(s) {
+					t.Fatalf("failed to add %v", s.Bound())
+				}
+			}
+			for _, s := range sprites[:200] {
+				if !grid.Remove(s) || !tree.Remove(s) {
+					t.Fatalf("failed to remove %v", s.Bound())
+				}
+			}
+			if grid.Count() != tree.Count() {
+				t.Fatalf("count %d differs from quadtree count %d", grid.Count(), tree.Count())
+			}
+			if !sliceutils.SameElements(grid.AllItems(), tree.AllItems()) {
+				t.Fatalf("AllItems differs from the quadtree")
+			}
+
+			for i, target := range sprites[200:300] {
+				margin := float64(i % 20)
+				expected := tree.FindNeighbors(target, margin)
+				if got := grid.FindNeighbors(target, margin); !sliceutils.SameElements(got, expected) {
+					t.Errorf("FindNeighbors(%v, %v) = %v, expected %v", target, margin, got, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestGrid_BatchUpdate(t *testing.T) {
+	grid := NewGrid(plane.NewToroidal2D(64.0, 64.0), 8.0)
+	defer grid.Close()
+
+	sprites := newSprites(rand.New(rand.NewSource(37)), 100, 64, 4)
+	for _, s := range sprites {
+		grid.Add(s)
+	}
+	outside := &sprite{AABB: geom.NewAABBAt(geom.NewVec(60.0, 60.0), 8, 8), id: -1}
+	replacement := &sprite{AABB: geom.NewAABBAt(geom.NewVec(1.0, 1.0), 2, 2), id: 1000}
+	grid.BatchUpdate(sprites[:40], []qtree.Item[float64]{replacement, outside}, true)
+
+	if grid.Count() != 61 {
+		t.Fatalf("expected 61 items after the batch, got %d", grid.Count())
+	}
+	expected := append([]qtree.Item[float64]{replacement}, sprites[40:]...)
+	if !sliceutils.SameElements(grid.AllItems(), expected) {
+		t.Errorf("unexpected items after the batch")
+	}
+	if grid.Remove(sprites[0]) {
+		t.Errorf("expected %v to be removed by the batch", sprites[0].Bound())
+	}
+}
+
+func ExampleGrid_FindNeighbors() {
+	grid := NewGrid(plane.NewToroidal2D(100.0, 100.0), 10.0)
+	defer grid.Close()
+
+	target := &sprite{AABB: geom.NewAABBAt(geom.NewVec(0.0, 50.0), 2, 2), id: 1}
+	grid.Add(target)
+	grid.Add(&sprite{AABB: geom.NewAABBAt(geom.NewVec(97.0, 50.0), 2, 2), id: 2})
+	grid.Add(&sprite{AABB: geom.NewAABBAt(geom.NewVec(50.0, 50.0), 2, 2), id: 3})
+
+	fmt.Println(grid.FindNeighbors(target, 5))
+
+	// Output:
+	// [#2]
+}
+
+var gridBenchSink int
+
+func Benchmark_Grid_vs_QuadTree_FindNeighbors(b *testing.B) {
+	space := plane.NewEuclidean2D(1024.0, 1024.0)
+	sprites := newSprites(rand.New(rand.NewSource(6)), 10000, 1024, 8)
+
+	grid := NewGrid(space, 16.0)
+	defer grid.Close()
+	tree := qtree.NewQuadTree(space)
+	defer tree.Close()
+	for _, s := range sprites {
+		grid.Add(s)
+		tree.Add(s)
+	}
+
+	b.Run("quadtree", func(b *testing.B) {
+		b.ReportAllocs()
+		i := 0
+		for b.Loop() {
+			gridBenchSink += len(tree.FindNeighbors(sprites[i%len(sprites)], 8))
+			i++
+		}
+	})
+	b.Run("grid", func(b *testing.B) {
+		b.ReportAllocs()
+		i := 0
+		for b.Loop() {
+			gridBenchSink += len(grid.FindNeighbors(sprites[i%len(sprites)], 8))
+			i++
+		}
+	})
+}
+
+func TestGrid_Conformance(t *testing.T) {
+	qtreetest.Run(t, func(space plane.Space2D[float64]) qtree.SpatialIndex[float64] {
+		return NewGrid(space, 8.0)
+	})
+}
@@ -0,0 +1,91 @@
+// Package hilbert maps grid cells and plane positions onto the Hilbert curve.
+// Unlike Z-order, consecutive Hilbert indices always denote adjacent cells, so
+// walking items by their index visits space without jumps between quadrants.
+package hilbert
+
+import (
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokg/pkg/plane"
+	"github.com/kjkrol/gokq/pkg/morton"
+)
+
+const (
+	MAX_ORDER     int = 32
+	DEFAULT_ORDER int = 16
+)
+
+// Encode returns the position of cell (x, y) along the Hilbert curve filling a
+// grid of 2^order cells per axis. The order is clamped to [1, MAX_ORDER] and
+// coordinates outside the grid are truncated to its low bits.
+func Encode(x, y uint32, order int) uint64 {
+	order = clampOrder(order)
+	mask := cellMask(order)
+	x, y = x&mask, y&mask
+
+	var d uint64
+	for s := uint32(1) << (order - 1); s > 0; s >>= 1 {
+		var rx, ry uint32
+		if x&s != 0 {
+			rx = 1
+		}
+		if y&s != 0 {
+			ry = 1
+		}
+		d += uint64(s) * uint64(s) * uint64((3*rx)^ry)
+		x, y = rotate(x, y, rx, ry, mask)
+	}
+	return d
+}
+
+// Decode returns the cell at position d along the Hilbert curve of the given
+// order; it is the inverse of Encode.
+func Decode(d uint64, order int) (x, y uint32) {
+	order = clampOrder(order)
+	for i := range order {
+		s := uint32(1) << i
+		rx := uint32(d>>1) & 1
+		ry := uint32(d^uint64(rx)) & 1
+		x, y = rotate(x, y, rx, ry, s-1)
+		x += s * rx
+		y += s * ry
+		d >>= 2
+	}
+	return x, y
+}
+
+// Index quantizes vec into the space's viewport (see morton.Quantize) and
+// returns its Hilbert index.
+func Index[T geom.Numeric](space plane.Space2D[T], vec geom.Vec[T], order int) uint64 {
+	order = clampOrder(order)
+	x, y := morton.Quantize(space, vec, order)
+	return Encode(x, y, order)
+}
+
+// Center returns the centre of the box, the point Index is usually applied to
+// when ordering boxes.
+func Center[T geom.Numeric](aabb geom.AABB[T]) geom.Vec[T] {
+	return geom.NewVec(
+		aabb.TopLeft.X+(aabb.BottomRight.X-aabb.TopLeft.X)/2,
+		aabb.TopLeft.Y+(aabb.BottomRight.Y-aabb.TopLeft.Y)/2,
+	)
+}
+
+// rotate flips the sub-square so that the curve keeps its orientation; mask
+// selects the bits of the current sub-square.
+func rotate(x, y, rx, ry, mask uint32) (uint32, uint32) {
+	if ry != 0 {
+		return x, y
+	}
+	if rx == 1 {
+		x, y = ^x&mask, ^y&mask
+	}
+	return y, x
+}
+
+func cellMask(order int) uint32 {
+	return uint32(uint64(1)<<order - 1)
+}
+
+func clampOrder(order int) int {
+	return min(max(order, 1), MAX_ORDER)
+}
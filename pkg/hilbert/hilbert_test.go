@@ -0,0 +1,81 @@
+package hilbert
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokg/pkg/plane"
+)
+
+func TestEncode_FirstOrder(t *testing.T) {
+	expected := [][2]uint32{{0, 0}, {0, 1}, {1, 1}, {1, 0}}
+	for d, cell := range expected {
+		if got := Encode(cell[0], cell[1], 1); got != uint64(d) {
+			t.Errorf("Encode(%d, %d, 1) = %d, expected %d", cell[0], cell[1], got, d)
+		}
+	}
+}
+
+func TestEncodeDecode_WalksAdjacentCells(t *testing.T) {
+	for _, order := range []int{1, 2, 3, 5, 8} {
+		side := uint64(1) << order
+		seen := make(map[uint64]bool, side*side)
+		prevX, prevY := Decode(0, order)
+		for d := range side * side {
+			x, y := Decode(d, order)
+			if uint64(x) >= side || uint64(y) >= side {
+				t.Fatalf("order %d: Decode(%d) = (%d,%d) lies outside the grid", order, d, x, y)
+			}
+			if got := Encode(x, y, order); got != d {
+				t.Fatalf("order %d: Encode(Decode(%d)) = %d", order, d, got)
+			}
+			if d > 0 && absDiff(x, prevX)+absDiff(y, prevY) != 1 {
+				t.Fatalf("order %d: cells %d and %d are not adjacent", order, d-1, d)
+			}
+			seen[uint64(x)<<32|uint64(y)] = true
+			prevX, prevY = x, y
+		}
+		if uint64(len(seen)) != side*side {
+			t.Errorf("order %d: visited %d cells, expected %d", order, len(seen), side*side)
+		}
+	}
+}
+
+func TestEncodeDecode_FullOrderRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewSource(32))
+	for range 100000 {
+		x, y := rnd.Uint32(), rnd.Uint32()
+		if dx, dy := Decode(Encode(x, y, MAX_ORDER), MAX_ORDER); dx != x || dy != y {
+			t.Fatalf("round trip of (%d,%d) gave (%d,%d)", x, y, dx, dy)
+		}
+	}
+}
+
+func TestIndex(t *testing.T) {
+	space := plane.NewEuclidean2D(16.0, 16.0)
+	testCases := []struct {
+		name     string
+		vec      geom.Vec[float64]
+		expected uint64
+	}{
+		{name: "topLeft", vec: geom.NewVec(1.0, 1.0), expected: 0},
+		{name: "bottomLeft", vec: geom.NewVec(1.0, 15.0), expected: 1},
+		{name: "bottomRight", vec: geom.NewVec(15.0, 15.0), expected: 2},
+		{name: "topRight", vec: geom.NewVec(15.0, 1.0), expected: 3},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Index(space, tc.vec, 1); got != tc.expected {
+				t.Errorf("Index(%v) = %d, expected %d", tc.vec, got, tc.expected)
+			}
+		})
+	}
+}
+
+func absDiff(a, b uint32) uint32 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
@@ -0,0 +1,82 @@
+package morton
+
+// QUADRANTS is the number of children of every location. A child's quadrant
+// index holds the column bit in bit 0 and the row bit in bit 1, so quadrants 0..3
+// are the top-left, top-right, bottom-left and bottom-right children.
+const QUADRANTS uint8 = 4
+
+// MAX_DEPTH is the deepest location representable with 64-bit codes.
+const MAX_DEPTH uint8 = 32
+
+// LocationCode addresses a cell of an implicit quadtree: Code interleaves the
+// cell's column and row at the given Depth, the root being the only cell at
+// depth 0.
+type LocationCode struct {
+	Code  uint64
+	Depth uint8
+}
+
+// NewLocationCode returns the location of cell (x, y) at depth.
+func NewLocationCode(x, y uint32, depth uint8) LocationCode {
+	return LocationCode{Code: Encode64(x, y), Depth: depth}
+}
+
+// Cell returns the column and row of the location within its depth.
+func (l LocationCode) Cell() (x, y uint32) {
+	return Decode64(l.Code)
+}
+
+// Parent returns the enclosing location; ok is false for the root.
+func (l LocationCode) Parent() (parent LocationCode, ok bool) {
+	if l.Depth == 0 {
+		return l, false
+	}
+	return LocationCode{Code: l.Code >> 2, Depth: l.Depth - 1}, true
+}
+
+// Child returns the child location in the given quadrant (see QUADRANTS).
+func (l LocationCode) Child(quadrant uint8) LocationCode {
+	return LocationCode{Code: l.Code<<2 | uint64(quadrant&(QUADRANTS-1)), Depth: l.Depth + 1}
+}
+
+// Quadrant returns the position of the location within its parent.
+func (l LocationCode) Quadrant() uint8 {
+	return uint8(l.Code & uint64(QUADRANTS-1))
+}
+
+// Contains reports whether other equals l or lies in l's subtree.
+func (l LocationCode) Contains(other LocationCode) bool {
+	if other.Depth < l.Depth {
+		return false
+	}
+	return other.Code>>(2*(other.Depth-l.Depth)) == l.Code
+}
+
+// Span returns the half-open range of full-resolution codes covered by the
+// location on a grid of the given depth.
+func (l LocationCode) Span(depth uint8) (start, end uint64) {
+	shift := 2 * uint64(depth-l.Depth)
+	return l.Code << shift, (l.Code + 1) << shift
+}
+
+// Neighbor returns the location shifted by (dx, dy) cells at the same depth; ok
+// is false when the shift leaves the grid.
+func (l LocationCode) Neighbor(dx, dy int) (neighbor LocationCode, ok bool) {
+	x, y := l.Cell()
+	side := int64(1) << l.Depth
+	nx, ny := int64(x)+int64(dx), int64(y)+int64(dy)
+	if nx < 0 || ny < 0 || nx >= side || ny >= side {
+		return l, false
+	}
+	return NewLocationCode(uint32(nx), uint32(ny), l.Depth), true
+}
+
+// WrappedNeighbor returns the location shifted by (dx, dy) cells at the same
+// depth, wrapping around the grid edges as on a toroidal plane.
+func (l LocationCode) WrappedNeighbor(dx, dy int) LocationCode {
+	x, y := l.Cell()
+	side := int64(1) << l.Depth
+	nx := ((int64(x)+int64(dx))%side + side) % side
+	ny := ((int64(y)+int64(dy))%side + side) % side
+	return NewLocationCode(uint32(nx), uint32(ny), l.Depth)
+}
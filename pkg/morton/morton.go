@@ -0,0 +1,79 @@
+// Package morton implements Z-order (Morton) codes for 2D grids: bit-interleaved
+// encoding and decoding, quantization of plane coordinates onto the grid, range
+// search within a Z-ordered box (BIGMIN/LITMAX) and location-code arithmetic for
+// implicit quadtrees.
+package morton
+
+const (
+	// X_MASK selects the bits of an interleaved code holding the column.
+	X_MASK uint64 = 0x5555555555555555
+	// Y_MASK selects the bits of an interleaved code holding the row.
+	Y_MASK uint64 = 0xAAAAAAAAAAAAAAAA
+)
+
+// Encode32 interleaves x over the even and y over the odd bits of the code.
+func Encode32(x, y uint16) uint32 {
+	return spread32(x) | spread32(y)<<1
+}
+
+// Decode32 is the inverse of Encode32.
+func Decode32(code uint32) (x, y uint16) {
+	return compact32(code), compact32(code >> 1)
+}
+
+// Encode64 interleaves x over the even and y over the odd bits of the code.
+func Encode64(x, y uint32) uint64 {
+	return spread64(x) | spread64(y)<<1
+}
+
+// Decode64 is the inverse of Encode64.
+func Decode64(code uint64) (x, y uint32) {
+	return compact64(code), compact64(code >> 1)
+}
+
+// spread64 inserts a zero bit between consecutive bits of v using the classic
+// "magic numbers" sequence, doubling the gaps at every step:
+//
+//	(v | v<<16) & 0x0000FFFF0000FFFF
+//	(v | v<<8)  & 0x00FF00FF00FF00FF
+//	(v | v<<4)  & 0x0F0F0F0F0F0F0F0F
+//	(v | v<<2)  & 0x3333333333333333
+//	(v | v<<1)  & 0x5555555555555555
+func spread64(v uint32) uint64 {
+	x := uint64(v)
+	x = (x | x<<16) & 0x0000FFFF0000FFFF
+	x = (x | x<<8) & 0x00FF00FF00FF00FF
+	x = (x | x<<4) & 0x0F0F0F0F0F0F0F0F
+	x = (x | x<<2) & 0x3333333333333333
+	x = (x | x<<1) & 0x5555555555555555
+	return x
+}
+
+// compact64 reverses spread64, gathering the even bits of code.
+func compact64(code uint64) uint32 {
+	x := code & 0x5555555555555555
+	x = (x | x>>1) & 0x3333333333333333
+	x = (x | x>>2) & 0x0F0F0F0F0F0F0F0F
+	x = (x | x>>4) & 0x00FF00FF00FF00FF
+	x = (x | x>>8) & 0x0000FFFF0000FFFF
+	x = (x | x>>16) & 0x00000000FFFFFFFF
+	return uint32(x)
+}
+
+func spread32(v uint16) uint32 {
+	x := uint32(v)
+	x = (x | x<<8) & 0x00FF00FF
+	x = (x | x<<4) & 0x0F0F0F0F
+	x = (x | x<<2) & 0x33333333
+	x = (x | x<<1) & 0x55555555
+	return x
+}
+
+func compact32(code uint32) uint16 {
+	x := code & 0x55555555
+	x = (x | x>>1) & 0x33333333
+	x = (x | x>>2) & 0x0F0F0F0F
+	x = (x | x>>4) & 0x00FF00FF
+	x = (x | x>>8) & 0x0000FFFF
+	return uint16(x)
+}
@@ -0,0 +1,193 @@
+package morton
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokg/pkg/plane"
+)
+
+// naiveEncode interleaves bit by bit.
+func naiveEncode(x, y uint64, bits int) uint64 {
+	var code uint64
+	for i := range bits {
+		code |= (x >> i & 1) << (2 * i)
+		code |= (y >> i & 1) << (2*i + 1)
+	}
+	return code
+}
+
+func naiveInBox(code uint64, x0, y0, x1, y1 uint32) bool {
+	x, y := Decode64(code)
+	return x >= x0 && x <= x1 && y >= y0 && y <= y1
+}
+
+func TestEncode32_ExhaustiveAgainstNaive(t *testing.T) {
+	for v := range 1 << 16 {
+		value := uint16(v)
+		if got, expected := Encode32(value, 0), uint32(naiveEncode(uint64(v), 0, 16)); got != expected {
+			t.Fatalf("Encode32(%d, 0) = %#x, expected %#x", v, got, expected)
+		}
+		if got, expected := Encode32(0, value), uint32(naiveEncode(0, uint64(v), 16)); got != expected {
+			t.Fatalf("Encode32(0, %d) = %#x, expected %#x", v, got, expected)
+		}
+		x, y := Decode32(Encode32(value, ^value))
+		if x != value || y != ^value {
+			t.Fatalf("Decode32 round trip of (%d, %d) gave (%d, %d)", value, ^value, x, y)
+		}
+	}
+}
+
+func TestEncode64_AgainstNaive(t *testing.T) {
+	for v := range 1 << 20 {
+		value := uint32(v)
+		if got, expected := Encode64(value, 0), naiveEncode(uint64(v), 0, 32); got != expected {
+			t.Fatalf("Encode64(%d, 0) = %#x, expected %#x", v, got, expected)
+		}
+	}
+	rnd := rand.New(rand.NewSource(31))
+	for range 100000 {
+		x, y := rnd.Uint32(), rnd.Uint32()
+		code := Encode64(x, y)
+		if expected := naiveEncode(uint64(x), uint64(y), 32); code != expected {
+			t.Fatalf("Encode64(%d, %d) = %#x, expected %#x", x, y, code, expected)
+		}
+		if dx, dy := Decode64(code); dx != x || dy != y {
+			t.Fatalf("Decode64 round trip of (%d, %d) gave (%d, %d)", x, y, dx, dy)
+		}
+	}
+}
+
+func TestBigMinLitMax_ExhaustiveAgainstNaive(t *testing.T) {
+	const side = 8
+	for x0 := range uint32(side) {
+		for x1 := x0; x1 < side; x1++ {
+			for y0 := range uint32(side) {
+				for y1 := y0; y1 < side; y1++ {
+					verifyZRange(t, x0, y0, x1, y1, side*side)
+				}
+			}
+		}
+	}
+}
+
+func TestBigMinLitMax_RandomBoxes(t *testing.T) {
+	rnd := rand.New(rand.NewSource(32))
+	for range 50 {
+		x0, x1 := uint32(rnd.Intn(32)), uint32(rnd.Intn(32))
+		y0, y1 := uint32(rnd.Intn(32)), uint32(rnd.Intn(32))
+		verifyZRange(t, min(x0, x1), min(y0, y1), max(x0, x1), max(y0, y1), 32*32)
+	}
+}
+
+func verifyZRange(t *testing.T, x0, y0, x1, y1 uint32, codes uint64) {
+	t.Helper()
+	zmin, zmax := Encode64(x0, y0), Encode64(x1, y1)
+	for code := range codes {
+		if got := InRange(code, zmin, zmax); got != naiveInBox(code, x0, y0, x1, y1) {
+			t.Fatalf("InRange(%d) in box (%d,%d)-(%d,%d) = %v", code, x0, y0, x1, y1, got)
+		}
+
+		expectedNext, expectedNextOK := uint64(0), false
+		for c := code + 1; c < codes; c++ {
+			if naiveInBox(c, x0, y0, x1, y1) {
+				expectedNext, expectedNextOK = c, true
+				break
+			}
+		}
+		if next, ok := BigMin(code, zmin, zmax); next != expectedNext || ok != expectedNextOK {
+			t.Fatalf("BigMin(%d) in box (%d,%d)-(%d,%d) = %d,%v expected %d,%v",
+				code, x0, y0, x1, y1, next, ok, expectedNext, expectedNextOK)
+		}
+
+		expectedPrev, expectedPrevOK := uint64(0), false
+		for c := int64(code) - 1; c >= 0; c-- {
+			if naiveInBox(uint64(c), x0, y0, x1, y1) {
+				expectedPrev, expectedPrevOK = uint64(c), true
+				break
+			}
+		}
+		if prev, ok := LitMax(code, zmin, zmax); prev != expectedPrev || ok != expectedPrevOK {
+			t.Fatalf("LitMax(%d) in box (%d,%d)-(%d,%d) = %d,%v expected %d,%v",
+				code, x0, y0, x1, y1, prev, ok, expectedPrev, expectedPrevOK)
+		}
+	}
+}
+
+func TestLocationCode_Arithmetic(t *testing.T) {
+	const depth = 4
+	side := uint32(1) << depth
+	for x := range side {
+		for y := range side {
+			loc := NewLocationCode(x, y, depth)
+
+			parent, ok := loc.Parent()
+			if px, py := parent.Cell(); !ok || px != x/2 || py != y/2 || parent.Depth != depth-1 {
+				t.Fatalf("Parent of (%d,%d) = %+v", x, y, parent)
+			}
+			if !parent.Contains(loc) || parent.Child(loc.Quadrant()) != loc {
+				t.Fatalf("Child/Contains mismatch for (%d,%d)", x, y)
+			}
+			if quadrant := loc.Quadrant(); uint32(quadrant) != (x&1)|(y&1)<<1 {
+				t.Fatalf("Quadrant of (%d,%d) = %d", x, y, quadrant)
+			}
+
+			for _, delta := range [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}, {1, 1}, {-3, 2}} {
+				nx, ny := int(x)+delta[0], int(y)+delta[1]
+				inside := nx >= 0 && ny >= 0 && nx < int(side) && ny < int(side)
+				neighbor, ok := loc.Neighbor(delta[0], delta[1])
+				if ok != inside {
+					t.Fatalf("Neighbor(%v) of (%d,%d) ok=%v", delta, x, y, ok)
+				}
+				if ok {
+					if cx, cy := neighbor.Cell(); int(cx) != nx || int(cy) != ny {
+						t.Fatalf("Neighbor(%v) of (%d,%d) = (%d,%d)", delta, x, y, cx, cy)
+					}
+				}
+				wx, wy := neighbor.Cell()
+				if !ok {
+					wx, wy = loc.WrappedNeighbor(delta[0], delta[1]).Cell()
+				}
+				if int(wx) != (nx+int(side))%int(side) || int(wy) != (ny+int(side))%int(side) {
+					t.Fatalf("WrappedNeighbor(%v) of (%d,%d) = (%d,%d)", delta, x, y, wx, wy)
+				}
+			}
+		}
+	}
+
+	if _, ok := (LocationCode{}).Parent(); ok {
+		t.Errorf("expected root to have no parent")
+	}
+	start, end := NewLocationCode(1, 0, 1).Span(3)
+	if start != 16 || end != 32 {
+		t.Errorf("Span = [%d,%d), expected [16,32)", start, end)
+	}
+}
+
+func TestQuantize(t *testing.T) {
+	testCases := []struct {
+		name     string
+		space    plane.Space2D[float64]
+		vec      geom.Vec[float64]
+		expected [2]uint32
+	}{
+		{name: "origin", space: plane.NewEuclidean2D(64.0, 32.0), vec: geom.NewVec(0.0, 0.0), expected: [2]uint32{0, 0}},
+		{name: "inside", space: plane.NewEuclidean2D(64.0, 32.0), vec: geom.NewVec(33.0, 9.0), expected: [2]uint32{8, 4}},
+		{name: "clampedEdge", space: plane.NewEuclidean2D(64.0, 32.0), vec: geom.NewVec(64.0, 40.0), expected: [2]uint32{15, 15}},
+		{name: "wrapped", space: plane.NewToroidal2D(64.0, 32.0), vec: geom.NewVec(-1.0, 34.0), expected: [2]uint32{15, 1}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			x, y := Quantize(tc.space, tc.vec, 4)
+			if x != tc.expected[0] || y != tc.expected[1] {
+				t.Errorf("Quantize(%v) = (%d,%d), expected %v", tc.vec, x, y, tc.expected)
+			}
+		})
+	}
+
+	zmin, zmax := AABBRange(plane.NewEuclidean2D(16.0, 16.0), geom.NewAABBAt(geom.NewVec(2.0, 2.0), 4, 4), 4)
+	if zmin != Encode64(2, 2) || zmax != Encode64(6, 6) {
+		t.Errorf("AABBRange = [%d,%d]", zmin, zmax)
+	}
+}
@@ -0,0 +1,51 @@
+package morton
+
+import (
+	"math"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokg/pkg/plane"
+)
+
+// MAX_BITS is the finest grid resolution supported by 64-bit codes.
+const MAX_BITS int = 32
+
+// Quantize maps vec onto a grid of 2^bits cells per axis laid over the space's
+// viewport. The vector is first normalized by the space, so positions outside
+// the viewport wrap on toroidal spaces and clamp on Euclidean ones.
+func Quantize[T geom.Numeric](space plane.Space2D[T], vec geom.Vec[T], bits int) (x, y uint32) {
+	viewport := space.Viewport()
+	normalized := space.WrapVec(vec).TopLeft
+	return quantizeAxis(normalized.X, viewport.TopLeft.X, viewport.BottomRight.X, bits),
+		quantizeAxis(normalized.Y, viewport.TopLeft.Y, viewport.BottomRight.Y, bits)
+}
+
+// Encode quantizes vec into the space's viewport and returns its Morton code.
+func Encode[T geom.Numeric](space plane.Space2D[T], vec geom.Vec[T], bits int) uint64 {
+	x, y := Quantize(space, vec, bits)
+	return Encode64(x, y)
+}
+
+// AABBRange returns the Z-order range [zmin, zmax] spanned by the box: the codes
+// of its quantized top-left and bottom-right corners. Every cell overlapping the
+// box has a code inside the range, though not every code in the range belongs
+// to the box; use BigMin to skip the gaps.
+func AABBRange[T geom.Numeric](space plane.Space2D[T], aabb geom.AABB[T], bits int) (zmin, zmax uint64) {
+	viewport := space.Viewport()
+	x0 := quantizeAxis(aabb.TopLeft.X, viewport.TopLeft.X, viewport.BottomRight.X, bits)
+	y0 := quantizeAxis(aabb.TopLeft.Y, viewport.TopLeft.Y, viewport.BottomRight.Y, bits)
+	x1 := quantizeAxis(aabb.BottomRight.X, viewport.TopLeft.X, viewport.BottomRight.X, bits)
+	y1 := quantizeAxis(aabb.BottomRight.Y, viewport.TopLeft.Y, viewport.BottomRight.Y, bits)
+	return Encode64(x0, y0), Encode64(x1, y1)
+}
+
+func quantizeAxis[T geom.Numeric](v, lo, hi T, bits int) uint32 {
+	bits = min(max(bits, 0), MAX_BITS)
+	cells := math.Ldexp(1, bits)
+	extent := float64(hi) - float64(lo)
+	if extent <= 0 {
+		return 0
+	}
+	c := math.Floor((float64(v) - float64(lo)) / extent * cells)
+	return uint32(min(max(c, 0), cells-1))
+}
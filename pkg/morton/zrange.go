@@ -0,0 +1,113 @@
+package morton
+
+// InRange reports whether code lies inside the box whose top-left corner has
+// code zmin and bottom-right corner has code zmax. Both coordinates are
+// compared independently, so codes inside [zmin, zmax] that fall outside the
+// box are rejected.
+func InRange(code, zmin, zmax uint64) bool {
+	x, y := code&X_MASK, code&Y_MASK
+	return x >= zmin&X_MASK && x <= zmax&X_MASK &&
+		y >= zmin&Y_MASK && y <= zmax&Y_MASK
+}
+
+// BigMin returns the smallest code greater than code that lies inside the box
+// [zmin, zmax] (Tropf & Herzog). ok is false when no such code exists. During a
+// scan over Z-ordered data, jumping to BigMin skips the runs of codes that leave
+// the box.
+func BigMin(code, zmin, zmax uint64) (next uint64, ok bool) {
+	if code >= zmax {
+		return 0, false
+	}
+	if code < zmin {
+		return zmin, true
+	}
+	code++
+	if InRange(code, zmin, zmax) {
+		return code, true
+	}
+	bigmin, found := uint64(0), false
+	for bit := 63; bit >= 0; bit-- {
+		mask := uint64(1) << bit
+		switch bitsAt(code, zmin, zmax, mask) {
+		case 0b001:
+			bigmin, found = loadOnes(zmin, bit), true
+			zmax = loadZeros(zmax, bit)
+		case 0b011:
+			return zmin, true
+		case 0b100:
+			return bigmin, found
+		case 0b101:
+			zmin = loadOnes(zmin, bit)
+		}
+	}
+	return bigmin, found
+}
+
+// LitMax returns the largest code smaller than code that lies inside the box
+// [zmin, zmax]; ok is false when no such code exists.
+func LitMax(code, zmin, zmax uint64) (prev uint64, ok bool) {
+	if code <= zmin {
+		return 0, false
+	}
+	if code > zmax {
+		return zmax, true
+	}
+	code--
+	if InRange(code, zmin, zmax) {
+		return code, true
+	}
+	litmax, found := uint64(0), false
+	for bit := 63; bit >= 0; bit-- {
+		mask := uint64(1) << bit
+		switch bitsAt(code, zmin, zmax, mask) {
+		case 0b001:
+			zmax = loadZeros(zmax, bit)
+		case 0b011:
+			return litmax, found
+		case 0b100:
+			return zmax, true
+		case 0b101:
+			litmax, found = loadZeros(zmax, bit), true
+			zmin = loadOnes(zmin, bit)
+		}
+	}
+	return litmax, found
+}
+
+// bitsAt packs the bits selected by mask as (code, zmin, zmax) from the most
+// significant position down.
+func bitsAt(code, zmin, zmax, mask uint64) uint8 {
+	var packed uint8
+	if code&mask != 0 {
+		packed |= 0b100
+	}
+	if zmin&mask != 0 {
+		packed |= 0b010
+	}
+	if zmax&mask != 0 {
+		packed |= 0b001
+	}
+	return packed
+}
+
+// loadOnes sets the bit and clears the lower bits of the same dimension
+// ("load 1000…" in the original paper).
+func loadOnes(v uint64, bit int) uint64 {
+	lower := sameDimensionBelow(bit)
+	return (v | 1<<bit) &^ lower
+}
+
+// loadZeros clears the bit and sets the lower bits of the same dimension
+// ("load 0111…" in the original paper).
+func loadZeros(v uint64, bit int) uint64 {
+	lower := sameDimensionBelow(bit)
+	return (v &^ (1 << bit)) | lower
+}
+
+func sameDimensionBelow(bit int) uint64 {
+	dimension := X_MASK
+	if bit%2 == 1 {
+		dimension = Y_MASK
+	}
+	return dimension & (1<<bit - 1)
+}
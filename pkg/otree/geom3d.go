@@ -0,0 +1,97 @@
+package otree
+
+import "github.com/kjkrol/gokg/pkg/geom"
+
+// Vec3 is a point or displacement in 3D space.
+type Vec3[T geom.Numeric] struct {
+	X, Y, Z T
+}
+
+func NewVec3[T geom.Numeric](x, y, z T) Vec3[T] {
+	return Vec3[T]{X: x, Y: y, Z: z}
+}
+
+func (v Vec3[T]) Add(other Vec3[T]) Vec3[T] {
+	return Vec3[T]{X: v.X + other.X, Y: v.Y + other.Y, Z: v.Z + other.Z}
+}
+
+func (v Vec3[T]) Sub(other Vec3[T]) Vec3[T] {
+	return Vec3[T]{X: v.X - other.X, Y: v.Y - other.Y, Z: v.Z - other.Z}
+}
+
+// AABB3 is an axis-aligned box spanning [Min, Max] on every axis, bounds
+// included; it is the 3D counterpart of geom.AABB.
+type AABB3[T geom.Numeric] struct {
+	Min, Max Vec3[T]
+}
+
+func NewAABB3[T geom.Numeric](minCorner, maxCorner Vec3[T]) AABB3[T] {
+	return AABB3[T]{Min: minCorner, Max: maxCorner}
+}
+
+// NewAABB3At builds a box with its minimum corner at pos and the given extents.
+func NewAABB3At[T geom.Numeric](pos Vec3[T], width, height, depth T) AABB3[T] {
+	return AABB3[T]{Min: pos, Max: pos.Add(NewVec3(width, height, depth))}
+}
+
+// NewAABB3Around builds a box centred at center reaching d along every axis.
+func NewAABB3Around[T geom.Numeric](center Vec3[T], d T) AABB3[T] {
+	return AABB3[T]{Min: center.Sub(NewVec3(d, d, d)), Max: center.Add(NewVec3(d, d, d))}
+}
+
+func (ab AABB3[T]) Center() Vec3[T] {
+	return NewVec3(
+		ab.Min.X+(ab.Max.X-ab.Min.X)/2,
+		ab.Min.Y+(ab.Max.Y-ab.Min.Y)/2,
+		ab.Min.Z+(ab.Max.Z-ab.Min.Z)/2,
+	)
+}
+
+// Contains reports whether other lies entirely inside the box.
+func (ab AABB3[T]) Contains(other AABB3[T]) bool {
+	return ab.Min.X <= other.Min.X && other.Max.X <= ab.Max.X &&
+		ab.Min.Y <= other.Min.Y && other.Max.Y <= ab.Max.Y &&
+		ab.Min.Z <= other.Min.Z && other.Max.Z <= ab.Max.Z
+}
+
+// Intersects reports whether the boxes overlap or touch.
+func (ab AABB3[T]) Intersects(other AABB3[T]) bool {
+	return ab.Min.X <= other.Max.X && other.Min.X <= ab.Max.X &&
+		ab.Min.Y <= other.Max.Y && other.Min.Y <= ab.Max.Y &&
+		ab.Min.Z <= other.Max.Z && other.Min.Z <= ab.Max.Z
+}
+
+// IntersectsVec reports whether v lies inside the box or on its boundary.
+func (ab AABB3[T]) IntersectsVec(v Vec3[T]) bool {
+	return ab.Min.X <= v.X && v.X <= ab.Max.X &&
+		ab.Min.Y <= v.Y && v.Y <= ab.Max.Y &&
+		ab.Min.Z <= v.Z && v.Z <= ab.Max.Z
+}
+
+// Split divides the box into eight octants. Octant i holds the upper half of
+// the X axis when bit 0 of i is set, of Y for bit 1 and of Z for bit 2, so the
+// first four octants follow the order of geom.AABB.Split.
+func (ab AABB3[T]) Split() [OCTANTS]AABB3[T] {
+	center := ab.Center()
+	var octants [OCTANTS]AABB3[T]
+	for i := range octants {
+		octant := ab
+		if i&1 == 0 {
+			octant.Max.X = center.X
+		} else {
+			octant.Min.X = center.X
+		}
+		if i&2 == 0 {
+			octant.Max.Y = center.Y
+		} else {
+			octant.Min.Y = center.Y
+		}
+		if i&4 == 0 {
+			octant.Max.Z = center.Z
+		} else {
+			octant.Min.Z = center.Z
+		}
+		octants[i] = octant
+	}
+	return octants
+}
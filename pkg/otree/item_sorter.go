@@ -0,0 +1,22 @@
+package otree
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+)
+
+func sortItems[T geom.Numeric](items []Item3D[T]) {
+	slices.SortFunc(items, func(a, b Item3D[T]) int {
+		ab, bb := a.Bound(), b.Bound()
+		return cmp.Or(
+			cmp.Compare(ab.Min.Z, bb.Min.Z),
+			cmp.Compare(ab.Min.Y, bb.Min.Y),
+			cmp.Compare(ab.Min.X, bb.Min.X),
+			cmp.Compare(ab.Max.Z, bb.Max.Z),
+			cmp.Compare(ab.Max.Y, bb.Max.Y),
+			cmp.Compare(ab.Max.X, bb.Max.X),
+		)
+	})
+}
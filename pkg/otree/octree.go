@@ -0,0 +1,114 @@
+// Package otree provides an octree, the 3D counterpart of qtree.QuadTree, with
+// the same capacity, depth and batch-update semantics, over Euclidean or
+// toroidal 3D spaces.
+package otree
+
+import (
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokq/pkg/dfs"
+)
+
+const (
+	CAPACITY  int = 8
+	MAX_DEPTH int = 10
+	OCTANTS   int = 8
+)
+
+// Item3D represents an object with an axis-aligned bounding box and a stable identifier.
+// SameID must return true when both operands refer to the same logical entity.
+type Item3D[T geom.Numeric] interface {
+	Bound() AABB3[T]
+	SameID(other Item3D[T]) bool
+}
+
+// Octree stores spatial items in a hierarchical grid for fast range queries.
+type Octree[T geom.Numeric] struct {
+	root        *Node[T]
+	appender    OctreeAppender[T]
+	remover     OctreeRemover[T]
+	finder      OctreeFinder[T]
+	coordinator BatchUpdateCoordinator[T]
+}
+
+// NewOctree builds an Octree covering the supplied space viewport.
+func NewOctree[T geom.Numeric](
+	space Space3D[T],
+	opts ...OctreeOption[T],
+) *Octree[T] {
+	root := newNode(space.Viewport(), nil)
+	ot := &Octree[T]{
+		root:     root,
+		appender: OctreeAppender[T]{maxDepth: MAX_DEPTH, capacity: CAPACITY},
+		remover:  OctreeRemover[T]{capacity: CAPACITY},
+		finder:   NewOctreeFinder(NewDefaultOctreeFinderStrategy(space)),
+	}
+	ot.coordinator = NewBatchUpdateCoordinator(ot.appender, ot.remover)
+	for _, opt := range opts {
+		opt(ot)
+	}
+	ot.coordinator.OctreeAppender = ot.appender
+	ot.coordinator.OctreeRemover = ot.remover
+	return ot
+}
+
+// Add inserts item into the tree; returns false if it cannot be placed.
+func (t *Octree[T]) Add(item Item3D[T]) bool {
+	return t.appender.add(t.root, item, 0)
+}
+
+// Remove deletes item from the tree; returns false when nothing was removed.
+func (t *Octree[T]) Remove(item Item3D[T]) bool {
+	return t.remover.remove(t.root, item)
+}
+
+// Close releases internal resources held by the tree.
+func (t *Octree[T]) Close() {
+	t.root.close()
+	t.coordinator.Close()
+}
+
+// Count returns the number of items stored in the tree.
+func (t *Octree[T]) Count() int {
+	total := 0
+
+	dfs.DFS(t.root, struct{}{}, func(node *Node[T], _ struct{}) (dfs.DFSControl, struct{}) {
+		total += len(node.items)
+		return dfs.Continue, struct{}{}
+	})
+
+	return total
+}
+
+// Depth reports the maximum depth for active nodes.
+func (t *Octree[T]) Depth() int {
+	return t.root.depth()
+}
+
+// AllItems returns a snapshot of every stored item.
+func (t *Octree[T]) AllItems() []Item3D[T] {
+	return t.root.allItems()
+}
+
+// LeafBounds returns the bounding boxes of all current leaf nodes in
+// depth-first order, visiting the children of a node in the order of
+// AABB3.Split.
+func (t *Octree[T]) LeafBounds() []AABB3[T] {
+	return t.root.leafBounds()
+}
+
+// FindNeighbors retrieves items within margin of the target's bounds.
+func (t *Octree[T]) FindNeighbors(target Item3D[T], margin T) []Item3D[T] {
+	return t.finder.FindNeighbors(t.root, target, margin)
+}
+
+// BatchUpdate removes a batch of items, re-inserts the supplied replacements and
+// optionally compresses the affected nodes. Compression is triggered when
+// triggerCompression is true or when the number of touched nodes exceeds the
+// configured threshold.
+func (t *Octree[T]) BatchUpdate(toRemove []Item3D[T], toAdd []Item3D[T], triggerCompression bool) {
+	if t == nil || t.root == nil {
+		return
+	}
+
+	t.coordinator.BatchUpdate(t.root, toRemove, toAdd, triggerCompression)
+}
@@ -0,0 +1,62 @@
+package otree
+
+import (
+	"github.com/kjkrol/gokg/pkg/geom"
+)
+
+type OctreeAppender[T geom.Numeric] struct {
+	maxDepth int
+	capacity int
+}
+
+func (oa OctreeAppender[T]) add(node *Node[T], item Item3D[T], depth int) bool {
+
+	if !node.bounds.Contains(item.Bound()) {
+		return false
+	}
+
+	if node.isNode() && depth < oa.maxDepth {
+		if child := node.findFittingChild(item.Bound()); child != nil {
+			if oa.add(child, item, depth+1) {
+				return true
+			}
+		}
+	}
+	node.items = append(node.items, item)
+
+	if len(node.items) > oa.capacity && node.isLeaf() && depth < oa.maxDepth {
+		oa.createChilds(node)
+		oa.redistributeItems(node, depth)
+	}
+
+	return true
+}
+
+func (oa OctreeAppender[T]) redistributeItems(node *Node[T], depth int) {
+	remaining := make([]Item3D[T], 0, len(node.items))
+	moved := 0
+
+	for _, item := range node.items {
+		if child := node.findFittingChild(item.Bound()); child != nil && oa.add(child, item, depth+1) {
+			moved++
+		} else {
+			remaining = append(remaining, item)
+		}
+	}
+	node.items = remaining
+
+	if moved == 0 {
+		for _, ch := range node.childs {
+			node.items = append(node.items, ch.items...)
+		}
+		node.childs = nil
+	}
+}
+
+func (oa OctreeAppender[T]) createChilds(node *Node[T]) {
+	octants := node.bounds.Split()
+	node.childs = make([]*Node[T], len(octants))
+	for i, box := range octants {
+		node.childs[i] = newNode(box, node)
+	}
+}
@@ -0,0 +1,32 @@
+package otree
+
+import (
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokq/pkg/dfs"
+)
+
+type OctreeFinder[T geom.Numeric] struct {
+	strategy OctreeFinderStrategy[T]
+}
+
+func NewOctreeFinder[T geom.Numeric](strategy OctreeFinderStrategy[T]) OctreeFinder[T] {
+	return OctreeFinder[T]{strategy: strategy}
+}
+
+func (of OctreeFinder[T]) FindNeighbors(root *Node[T], target Item3D[T], margin T) []Item3D[T] {
+
+	nodeIntersectionDetection := of.strategy.NodeIntersectionDetectionFactory(target, margin)
+	itemsInRangeDetection := of.strategy.ItemsInRangeDetectionFactory(target, margin)
+	neighbors := make([]Item3D[T], 0)
+
+	dfs.DFS(root, struct{}{}, func(node *Node[T], _ struct{}) (dfs.DFSControl, struct{}) {
+		if !nodeIntersectionDetection(*node) {
+			return dfs.SkipChildren, struct{}{}
+		}
+		itemsInRangeDetection(*node, func(item Item3D[T]) { neighbors = append(neighbors, item) })
+		return dfs.Continue, struct{}{}
+	})
+
+	sortItems(neighbors)
+	return neighbors
+}
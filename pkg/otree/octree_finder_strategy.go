@@ -0,0 +1,57 @@
+package otree
+
+import (
+	"github.com/kjkrol/gokg/pkg/geom"
+)
+
+type NodeIntersectionDetection[T geom.Numeric] func(Node[T]) bool
+type ItemsInRangeDetection[T geom.Numeric] func(Node[T], func(Item3D[T]))
+
+type OctreeFinderStrategy[T geom.Numeric] interface {
+	NodeIntersectionDetectionFactory(target Item3D[T], margin T) NodeIntersectionDetection[T]
+	ItemsInRangeDetectionFactory(target Item3D[T], margin T) ItemsInRangeDetection[T]
+}
+
+// ----------------- DefaultOctreeFinderStrategy -----------------
+
+type DefaultOctreeFinderStrategy[T geom.Numeric] struct {
+	Space3D[T]
+}
+
+func NewDefaultOctreeFinderStrategy[T geom.Numeric](
+	space Space3D[T],
+) OctreeFinderStrategy[T] {
+	return DefaultOctreeFinderStrategy[T]{space}
+}
+
+func (s DefaultOctreeFinderStrategy[T]) NodeIntersectionDetectionFactory(
+	target Item3D[T],
+	margin T,
+) NodeIntersectionDetection[T] {
+	fragments := s.Space3D.Expand(target.Bound(), margin)
+	return func(node Node[T]) bool {
+		for _, fragment := range fragments {
+			if node.bounds.Intersects(fragment) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func (s DefaultOctreeFinderStrategy[T]) ItemsInRangeDetectionFactory(
+	target Item3D[T],
+	margin T,
+) ItemsInRangeDetection[T] {
+	boundingBoxDistance := s.Space3D.AABBDistance()
+	return func(node Node[T], inRangeApply func(Item3D[T])) {
+		for _, item := range node.items {
+			if item.SameID(target) {
+				continue
+			}
+			if boundingBoxDistance(target.Bound(), item.Bound()) <= margin {
+				inRangeApply(item)
+			}
+		}
+	}
+}
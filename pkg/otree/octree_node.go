@@ -0,0 +1,68 @@
+package otree
+
+import (
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokq/pkg/dfs"
+)
+
+type Node[T geom.Numeric] struct {
+	bounds AABB3[T]
+	items  []Item3D[T]
+	parent *Node[T]
+	childs []*Node[T]
+}
+
+func newNode[T geom.Numeric](bounds AABB3[T], parent *Node[T]) *Node[T] {
+	return &Node[T]{bounds: bounds, items: make([]Item3D[T], 0), parent: parent}
+}
+
+func (n *Node[T]) isLeaf() bool { return len(n.childs) == 0 }
+func (n *Node[T]) isNode() bool { return len(n.childs) > 0 }
+
+func (n *Node[T]) findFittingChild(r AABB3[T]) *Node[T] {
+	for _, child := range n.childs {
+		if child.bounds.Contains(r) {
+			return child
+		}
+	}
+	return nil
+}
+
+func (n *Node[T]) Children() []*Node[T] {
+	return n.childs
+}
+
+// Bounds returns the octant assigned to the node.
+func (n *Node[T]) Bounds() AABB3[T] {
+	return n.bounds
+}
+
+func (n *Node[T]) close() {
+	dfs.DFSPostOrder(n, func(node *Node[T]) dfs.DFSControl {
+		node.items = nil
+		node.childs = nil
+		node.parent = nil
+		return dfs.Continue
+	})
+}
+
+func (n *Node[T]) allItems() []Item3D[T] {
+	items := dfs.Fold(n, []Item3D[T]{}, func(items []Item3D[T], node *Node[T]) []Item3D[T] {
+		return append(items, node.items...)
+	})
+	sortItems(items)
+	return items
+}
+
+func (n *Node[T]) depth() int {
+	return dfs.MaxDepth(n)
+}
+
+func (n *Node[T]) leafBounds() []AABB3[T] {
+	leaves := dfs.Leaves(n)
+	boxes := make([]AABB3[T], len(leaves))
+	for i, leaf := range leaves {
+		boxes[i] = leaf.bounds
+	}
+	return boxes
+}
@@ -0,0 +1,30 @@
+package otree
+
+import (
+	"github.com/kjkrol/gokg/pkg/geom"
+)
+
+type OctreeOption[T geom.Numeric] func(*Octree[T])
+
+func WithMaxDepth[T geom.Numeric](depth int) OctreeOption[T] {
+	return func(ot *Octree[T]) {
+		ot.appender.maxDepth = depth
+	}
+}
+
+func WithCapacity[T geom.Numeric](capacity int) OctreeOption[T] {
+	return func(ot *Octree[T]) {
+		if capacity > 0 {
+			ot.appender.capacity = capacity
+			ot.remover.capacity = capacity
+		}
+	}
+}
+
+func WithBatchCompressThreshold[T geom.Numeric](threshold int) OctreeOption[T] {
+	return func(ot *Octree[T]) {
+		if threshold > 0 {
+			ot.coordinator.threshold = threshold
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package otree
+
+import (
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokq/pkg/dfs"
+)
+
+type OctreeRemover[T geom.Numeric] struct {
+	capacity int
+}
+
+func (or OctreeRemover[T]) remove(node *Node[T], item Item3D[T]) bool {
+	if removedNode, ok := or.removeInternal(node, item); ok {
+		or.compressPath(removedNode)
+		return true
+	}
+	return false
+}
+
+func (or OctreeRemover[T]) RemoveWithoutCompression(node *Node[T], item Item3D[T]) (*Node[T], bool) {
+	return or.removeInternal(node, item)
+}
+
+func (or OctreeRemover[T]) removeInternal(node *Node[T], item Item3D[T]) (*Node[T], bool) {
+	if node.isNode() {
+		if child := node.findFittingChild(item.Bound()); child != nil {
+			if removedNode, ok := or.removeInternal(child, item); ok {
+				return removedNode, true
+			}
+		}
+	}
+
+	for i, it := range node.items {
+		if it == item {
+			node.items = append(node.items[:i], node.items[i+1:]...)
+			return node, true
+		}
+	}
+
+	return nil, false
+}
+
+func (or OctreeRemover[T]) compressPath(node *Node[T]) {
+	for n := node; n != nil; n = n.parent {
+		or.tryCompress(n)
+	}
+}
+
+func (or OctreeRemover[T]) tryCompress(node *Node[T]) {
+	if !node.isNode() {
+		return
+	}
+
+	collected := or.collectItems(node)
+	if len(collected) <= or.capacity {
+		node.items = collected
+		node.childs = nil
+	}
+}
+
+func (or OctreeRemover[T]) collectItems(n *Node[T]) []Item3D[T] {
+	items := make([]Item3D[T], 0, len(n.items))
+	dfs.DFS(n, struct{}{}, func(node *Node[T], _ struct{}) (dfs.DFSControl, struct{}) {
+		items = append(items, node.items...)
+		return dfs.Continue, struct{}{}
+	})
+	return items
+}
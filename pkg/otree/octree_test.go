@@ -0,0 +1,224 @@
+package otree
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/kjkrol/goku/pkg/sliceutils"
+)
+
+type TestItem3D[T int | uint32 | float64] struct {
+	AABB3[T]
+	id int
+}
+
+var testItemSeq int
+
+func (it *TestItem3D[T]) Bound() AABB3[T] { return it.AABB3 }
+func (it *TestItem3D[T]) SameID(other Item3D[T]) bool {
+	o, ok := other.(*TestItem3D[T])
+	return ok && o.id == it.id
+}
+func (it *TestItem3D[T]) String() string {
+	return fmt.Sprintf("%v-%v", it.Min, it.Max)
+}
+
+func newTestItem3D[T int | uint32 | float64](x, y, z, w, h, d T) *TestItem3D[T] {
+	testItemSeq++
+	return &TestItem3D[T]{AABB3: NewAABB3At(NewVec3(x, y, z), w, h, d), id: testItemSeq}
+}
+
+func addRandomItems(tree *Octree[float64], rnd *rand.Rand, n int) []Item3D[float64] {
+	items := make([]Item3D[float64], 0, n)
+	for range n {
+		size := 1 + rnd.Float64()*4
+		item := newTestItem3D(rnd.Float64()*(64-size), rnd.Float64()*(64-size), rnd.Float64()*(64-size), size, size, size)
+		if !tree.Add(item) {
+			panic("item outside the viewport")
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func TestOctree_FindNeighborsMatchesBruteForce(t *testing.T) {
+	for _, space := range []Space3D[float64]{
+		NewEuclidean3D(64.0, 64.0, 64.0),
+		NewToroidal3D(64.0, 64.0, 64.0),
+	} {
+		t.Run(space.Name(), func(t *testing.T) {
+			tree := NewOctree(space)
+			defer tree.Close()
+
+			rnd := rand.New(rand.NewSource(33))
+			items := addRandomItems(tree, rnd, 600)
+			if tree.Count() != len(items) {
+				t.Fatalf("expected %d items, got %d", len(items), tree.Count())
+			}
+			if tree.Depth() < 2 {
+				t.Fatalf("expected the tree to split, depth %d", tree.Depth())
+			}
+
+			distance := space.AABBDistance()
+			for i, target := range items[:100] {
+				margin := float64(i % 12)
+				expected := []Item3D[float64]{}
+				for _, item := range items {
+					if item != target && distance(target.Bound(), item.Bound()) <= margin {
+						expected = append(expected, item)
+					}
+				}
+				got := tree.FindNeighbors(target, margin)
+				if !sliceutils.SameElements(got, expected) {
+					t.Fatalf("FindNeighbors(%v, %v) = %v, expected %v", target, margin, got, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestOctree_AddRemoveCompresses(t *testing.T) {
+	tree := NewOctree(NewEuclidean3D(16, 16, 16), WithCapacity[int](2))
+	defer tree.Close()
+
+	if tree.Add(newTestItem3D(10, 10, 10, 8, 8, 8)) {
+		t.Errorf("expected item outside the viewport to be rejected")
+	}
+
+	items := []*TestItem3D[int]{
+		newTestItem3D(1, 1, 1, 1, 1, 1),
+		newTestItem3D(9, 1, 1, 1, 1, 1),
+		newTestItem3D(1, 9, 9, 1, 1, 1),
+		newTestItem3D(9, 9, 9, 1, 1, 1),
+	}
+	for _, item := range items {
+		tree.Add(item)
+	}
+	if leaves := len(tree.LeafBounds()); leaves != OCTANTS {
+		t.Fatalf("expected %d leaves after the split, got %d", OCTANTS, leaves)
+	}
+
+	for _, item := range items[:2] {
+		if !tree.Remove(item) {
+			t.Fatalf("failed to remove %v", item)
+		}
+	}
+	if leaves := len(tree.LeafBounds()); leaves != 1 {
+		t.Errorf("expected the root to be compressed into a single leaf, got %d leaves", leaves)
+	}
+	if tree.Remove(items[0]) {
+		t.Errorf("expected second removal to fail")
+	}
+	if got := tree.AllItems(); !slices.Equal(got, []Item3D[int]{items[2], items[3]}) {
+		t.Errorf("AllItems = %v", got)
+	}
+}
+
+func TestOctree_BatchUpdate(t *testing.T) {
+	tree := NewOctree(NewToroidal3D(64.0, 64.0, 64.0))
+	defer tree.Close()
+
+	rnd := rand.New(rand.NewSource(34))
+	items := addRandomItems(tree, rnd, 200)
+
+	toAdd := []Item3D[float64]{newTestItem3D(1.0, 1.0, 1.0, 0, 0, 0), newTestItem3D(63.0, 63.0, 63.0, 0, 0, 0)}
+	tree.BatchUpdate(items[:80], toAdd, true)
+
+	expected := append(slices.Clone(items[80:]), toAdd...)
+	if got := tree.AllItems(); !sliceutils.SameElements(got, expected) {
+		t.Fatalf("expected %d items after batch update, got %d", len(expected), len(got))
+	}
+	neighbors := tree.FindNeighbors(toAdd[0], 4)
+	if !slices.Contains(neighbors, toAdd[1]) {
+		t.Errorf("expected corner items to meet across the wrapped edges, got %v", neighbors)
+	}
+}
+
+func TestOctree_FindNeighborsUnsignedNearOrigin(t *testing.T) {
+	for _, space := range []Space3D[uint32]{
+		NewEuclidean3D[uint32](32, 32, 32),
+		NewToroidal3D[uint32](32, 32, 32),
+	} {
+		t.Run(space.Name(), func(t *testing.T) {
+			tree := NewOctree(space)
+			defer tree.Close()
+
+			items := []Item3D[uint32]{}
+			for _, x := range []uint32{0, 1, 3, 6, 30} {
+				item := newTestItem3D[uint32](x, 1, 1, 1, 1, 1)
+				tree.Add(item)
+				items = append(items, item)
+			}
+
+			distance := space.AABBDistance()
+			for _, target := range items {
+				for _, margin := range []uint32{0, 2, 40} {
+					expected := []Item3D[uint32]{}
+					for _, item := range items {
+						if item != target && distance(target.Bound(), item.Bound()) <= margin {
+							expected = append(expected, item)
+						}
+					}
+					got := tree.FindNeighbors(target, margin)
+					if !sliceutils.SameElements(got, expected) {
+						t.Fatalf("FindNeighbors(%v, %d) = %v, expected %v", target, margin, got, expected)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSpace3D_FragmentsAndDistance(t *testing.T) {
+	toroidal := NewToroidal3D(10, 10, 10)
+	euclidean := NewEuclidean3D(10, 10, 10)
+	box := NewAABB3(NewVec3(8, 4, -1), NewVec3(12, 5, 1))
+
+	testCases := []struct {
+		name      string
+		space     Space3D[int]
+		fragments int
+	}{
+		{name: "toroidalSplitsOnTwoAxes", space: toroidal, fragments: 4},
+		{name: "euclideanClips", space: euclidean, fragments: 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fragments := tc.space.Fragments(box)
+			if len(fragments) != tc.fragments {
+				t.Fatalf("expected %d fragments, got %v", tc.fragments, fragments)
+			}
+			for _, fragment := range fragments {
+				if !tc.space.Viewport().Contains(fragment) {
+					t.Errorf("fragment %v leaves the viewport", fragment)
+				}
+			}
+		})
+	}
+
+	a := NewAABB3(NewVec3(0, 0, 0), NewVec3(1, 1, 1))
+	b := NewAABB3(NewVec3(9, 0, 9), NewVec3(9, 1, 9))
+	if got := toroidal.AABBDistance()(a, b); got != 2 {
+		t.Errorf("toroidal distance = %d, expected 2", got)
+	}
+	if got := euclidean.AABBDistance()(a, b); got != 12 {
+		t.Errorf("euclidean distance = %d, expected 12", got)
+	}
+}
+
+func ExampleOctree_FindNeighbors() {
+	tree := NewOctree(NewToroidal3D(100.0, 100.0, 100.0))
+	defer tree.Close()
+
+	probe := newTestItem3D(0.5, 50.0, 50.0, 1, 1, 1)
+	tree.Add(probe)
+	tree.Add(newTestItem3D(98.0, 50.0, 50.0, 1, 1, 1))
+	tree.Add(newTestItem3D(50.0, 50.0, 50.0, 1, 1, 1))
+
+	fmt.Println(tree.FindNeighbors(probe, 2))
+
+	// Output:
+	// [{98 50 50}-{99 51 51}]
+}
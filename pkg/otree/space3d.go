@@ -0,0 +1,206 @@
+package otree
+
+import (
+	"math"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+)
+
+const (
+	modeEuclidean3D = "Euclidean3D"
+	modeToroidal3D  = "Toroidal3D"
+)
+
+// Space3D describes the volume an Octree covers: its viewport, how boxes are
+// mapped into it and how far apart two boxes are. It is the 3D counterpart of
+// plane.Space2D.
+type Space3D[T geom.Numeric] interface {
+	Name() string
+	Viewport() AABB3[T]
+	// WrapVec maps v into the viewport: it wraps on toroidal spaces and clamps
+	// on Euclidean ones.
+	WrapVec(v Vec3[T]) Vec3[T]
+	// Fragments returns the parts of aabb lying inside the viewport. On toroidal
+	// spaces a box crossing an edge re-enters on the opposite side, giving up
+	// to eight fragments.
+	Fragments(aabb AABB3[T]) []AABB3[T]
+	// Expand returns the fragments of aabb grown by margin on every side.
+	Expand(aabb AABB3[T], margin T) []AABB3[T]
+	AABBDistance() AABBDistance3D[T]
+}
+
+// AABBDistance3D measures the gap between two boxes in the metric of a space.
+type AABBDistance3D[T geom.Numeric] func(aabb1, aabb2 AABB3[T]) T
+
+type space3d[T geom.Numeric] struct {
+	size     Vec3[T]
+	viewport AABB3[T]
+}
+
+func newSpace3D[T geom.Numeric](sizeX, sizeY, sizeZ T) space3d[T] {
+	size := NewVec3(sizeX, sizeY, sizeZ)
+	return space3d[T]{size: size, viewport: NewAABB3(Vec3[T]{}, size)}
+}
+
+func (s space3d[T]) Viewport() AABB3[T] { return s.viewport }
+
+// ----------------- Euclidean3D -----------------
+
+// NewEuclidean3D constructs a bounded 3D space without wrap-around.
+func NewEuclidean3D[T geom.Numeric](sizeX, sizeY, sizeZ T) Space3D[T] {
+	return euclidean3d[T]{newSpace3D(sizeX, sizeY, sizeZ)}
+}
+
+type euclidean3d[T geom.Numeric] struct{ space3d[T] }
+
+func (s euclidean3d[T]) Name() string { return modeEuclidean3D }
+
+func (s euclidean3d[T]) WrapVec(v Vec3[T]) Vec3[T] {
+	return NewVec3(clamp(v.X, s.size.X), clamp(v.Y, s.size.Y), clamp(v.Z, s.size.Z))
+}
+
+func (s euclidean3d[T]) Fragments(aabb AABB3[T]) []AABB3[T] {
+	if !s.viewport.Intersects(aabb) {
+		return nil
+	}
+	return []AABB3[T]{NewAABB3(s.WrapVec(aabb.Min), s.WrapVec(aabb.Max))}
+}
+
+func (s euclidean3d[T]) Expand(aabb AABB3[T], margin T) []AABB3[T] {
+	return s.Fragments(expand(aabb, margin, s.size, false))
+}
+
+func (s euclidean3d[T]) AABBDistance() AABBDistance3D[T] {
+	return func(aabb1, aabb2 AABB3[T]) T {
+		return length(
+			axisDistance(aabb1.Min.X, aabb1.Max.X, aabb2.Min.X, aabb2.Max.X),
+			axisDistance(aabb1.Min.Y, aabb1.Max.Y, aabb2.Min.Y, aabb2.Max.Y),
+			axisDistance(aabb1.Min.Z, aabb1.Max.Z, aabb2.Min.Z, aabb2.Max.Z),
+		)
+	}
+}
+
+// ----------------- Toroidal3D -----------------
+
+// NewToroidal3D constructs a 3D space with wrap-around behaviour on all axes.
+func NewToroidal3D[T geom.Numeric](sizeX, sizeY, sizeZ T) Space3D[T] {
+	return toroidal3d[T]{newSpace3D(sizeX, sizeY, sizeZ)}
+}
+
+type toroidal3d[T geom.Numeric] struct{ space3d[T] }
+
+func (s toroidal3d[T]) Name() string { return modeToroidal3D }
+
+func (s toroidal3d[T]) WrapVec(v Vec3[T]) Vec3[T] {
+	return NewVec3(wrap(v.X, s.size.X), wrap(v.Y, s.size.Y), wrap(v.Z, s.size.Z))
+}
+
+func (s toroidal3d[T]) Fragments(aabb AABB3[T]) []AABB3[T] {
+	xs := wrapInterval(aabb.Min.X, aabb.Max.X, s.size.X)
+	ys := wrapInterval(aabb.Min.Y, aabb.Max.Y, s.size.Y)
+	zs := wrapInterval(aabb.Min.Z, aabb.Max.Z, s.size.Z)
+	fragments := make([]AABB3[T], 0, len(xs)*len(ys)*len(zs))
+	for _, x := range xs {
+		for _, y := range ys {
+			for _, z := range zs {
+				fragments = append(fragments, NewAABB3(NewVec3(x[0], y[0], z[0]), NewVec3(x[1], y[1], z[1])))
+			}
+		}
+	}
+	return fragments
+}
+
+func (s toroidal3d[T]) Expand(aabb AABB3[T], margin T) []AABB3[T] {
+	return s.Fragments(expand(aabb, margin, s.size, true))
+}
+
+// AABBDistance measures the gap on every axis along the shorter way around.
+func (s toroidal3d[T]) AABBDistance() AABBDistance3D[T] {
+	return func(aabb1, aabb2 AABB3[T]) T {
+		return length(
+			cyclicAxisDistance(aabb1.Min.X, aabb1.Max.X, aabb2.Min.X, aabb2.Max.X, s.size.X),
+			cyclicAxisDistance(aabb1.Min.Y, aabb1.Max.Y, aabb2.Min.Y, aabb2.Max.Y, s.size.Y),
+			cyclicAxisDistance(aabb1.Min.Z, aabb1.Max.Z, aabb2.Min.Z, aabb2.Max.Z, s.size.Z),
+		)
+	}
+}
+
+// ----------------- helpers -----------------
+
+// expand grows aabb by margin on every axis. The lower bound never drops below
+// the origin, so unsigned coordinates cannot underflow: it is clamped at 0,
+// or on cyclic spaces the interval is shifted up by one period and wrapped
+// back by Fragments.
+func expand[T geom.Numeric](aabb AABB3[T], margin T, size Vec3[T], cyclic bool) AABB3[T] {
+	x0, x1 := expandInterval(aabb.Min.X, aabb.Max.X, margin, size.X, cyclic)
+	y0, y1 := expandInterval(aabb.Min.Y, aabb.Max.Y, margin, size.Y, cyclic)
+	z0, z1 := expandInterval(aabb.Min.Z, aabb.Max.Z, margin, size.Z, cyclic)
+	return NewAABB3(NewVec3(x0, y0, z0), NewVec3(x1, y1, z1))
+}
+
+func expandInterval[T geom.Numeric](lo, hi, margin, size T, cyclic bool) (T, T) {
+	switch {
+	case lo >= margin:
+		return lo - margin, hi + margin
+	case !cyclic:
+		return 0, hi + margin
+	case margin >= size:
+		return 0, size
+	}
+	return lo + size - margin, hi + size + margin
+}
+
+// wrapInterval maps [lo, hi] onto [0, size], splitting it in two when it
+// crosses the upper edge.
+func wrapInterval[T geom.Numeric](lo, hi, size T) [][2]T {
+	if hi-lo >= size {
+		return [][2]T{{0, size}}
+	}
+	start := wrap(lo, size)
+	end := start + (hi - lo)
+	if end <= size {
+		return [][2]T{{start, end}}
+	}
+	return [][2]T{{start, size}, {0, end - size}}
+}
+
+func axisDistance[T geom.Numeric](aMin, aMax, bMin, bMax T) T {
+	if aMax < bMin {
+		return bMin - aMax
+	}
+	if bMax < aMin {
+		return aMin - bMax
+	}
+	return 0
+}
+
+func cyclicAxisDistance[T geom.Numeric](aMin, aMax, bMin, bMax, size T) T {
+	gap := axisDistance(aMin, aMax, bMin, bMax)
+	gap = min(gap, axisDistance(aMin, aMax, bMin+size, bMax+size))
+	return min(gap, axisDistance(aMin+size, aMax+size, bMin, bMax))
+}
+
+func wrap[T geom.Numeric](v, size T) T {
+	if size <= 0 {
+		return 0
+	}
+	m := math.Mod(float64(v), float64(size))
+	if m < 0 {
+		m += float64(size)
+	}
+	return T(m)
+}
+
+func clamp[T geom.Numeric](v, size T) T {
+	return min(max(v, 0), size)
+}
+
+// length returns the Euclidean norm of (dx, dy, dz), rounded up for integer
+// coordinates as plane.Space2D does.
+func length[T geom.Numeric](dx, dy, dz T) T {
+	l := math.Sqrt(float64(dx)*float64(dx) + float64(dy)*float64(dy) + float64(dz)*float64(dz))
+	if T(1)/2 == 0 {
+		return T(math.Ceil(l))
+	}
+	return T(l)
+}
@@ -1,11 +1,29 @@
 package qtree
 
 import (
+	"cmp"
+	"slices"
 	"sort"
 
 	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokg/pkg/plane"
+	"github.com/kjkrol/gokq/pkg/hilbert"
 )
 
+// SortItems orders items by the top-left corner of their bounds, row by row,
+// then by the bottom-right corner. Query results of the tree follow this order,
+// and other indexes use it to match.
+func SortItems[T geom.Numeric](items []Item[T]) {
+	sortItems(items)
+}
+
+// SameItem reports whether a and b stand for the same entity: both nil, the
+// same value, or items reporting the same ID. Indexes use it to find the
+// stored copy of an item to remove.
+func SameItem[T geom.Numeric](a, b Item[T]) bool {
+	return sameItem(a, b)
+}
+
 func sortItems[T geom.Numeric](items []Item[T]) {
 	sort.Slice(items, func(i, j int) bool {
 		ai, aj := items[i].Bound(), items[j].Bound()
@@ -19,3 +37,21 @@ func sortItems[T geom.Numeric](items []Item[T]) {
 		return first.Equals(ai)
 	})
 }
+
+// sortItemsByHilbert orders items by the Hilbert index of their centres within
+// the space's viewport; items sharing an index keep their relative order.
+func sortItemsByHilbert[T geom.Numeric](space plane.Space2D[T], items []Item[T]) {
+	type keyed struct {
+		key  uint64
+		item Item[T]
+	}
+	keys := make([]keyed, len(items))
+	for i, item := range items {
+		center := hilbert.Center(item.Bound())
+		keys[i] = keyed{key: hilbert.Index(space, center, hilbert.DEFAULT_ORDER), item: item}
+	}
+	slices.SortStableFunc(keys, func(a, b keyed) int { return cmp.Compare(a.key, b.key) })
+	for i, k := range keys {
+		items[i] = k.item
+	}
+}
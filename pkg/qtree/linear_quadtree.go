@@ -0,0 +1,281 @@
+package qtree
+
+import (
+	"iter"
+	"math"
+	"math/bits"
+	"slices"
+	"sort"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokg/pkg/plane"
+	"github.com/kjkrol/gokq/pkg/morton"
+)
+
+const (
+	LINEAR_MAX_DEPTH int = 16
+	linearDepthLimit int = 31
+)
+
+// LocationCode addresses a cell of the implicit quadtree used by
+// LinearQuadTree; see morton.LocationCode.
+type LocationCode = morton.LocationCode
+
+// LinearQuadTree is a pointerless quadtree. Every item is assigned the deepest
+// cell that fully contains its bounds, and the items are kept in a single slice
+// sorted by that cell's Z-order position, so a subtree always occupies a
+// contiguous range of the slice. It offers the same query interface as QuadTree.
+type LinearQuadTree[T geom.Numeric] struct {
+	space    plane.Space2D[T]
+	viewport geom.AABB[T]
+	maxDepth int
+	entries  []linearEntry[T]
+}
+
+type LinearQuadTreeOption[T geom.Numeric] func(*LinearQuadTree[T])
+
+// WithLinearMaxDepth sets the resolution of the implicit grid: the viewport is
+// divided into 2^depth cells per axis. Depth is capped at 31.
+func WithLinearMaxDepth[T geom.Numeric](depth int) LinearQuadTreeOption[T] {
+	return func(t *LinearQuadTree[T]) {
+		if depth >= 0 {
+			t.maxDepth = min(depth, linearDepthLimit)
+		}
+	}
+}
+
+// linearEntry stores an item with the full-resolution Z-order key of the first
+// grid cell of its location and the depth of that location.
+type linearEntry[T geom.Numeric] struct {
+	key   uint64
+	depth uint8
+	item  Item[T]
+}
+
+func (e linearEntry[T]) before(key uint64, depth uint8) bool {
+	return e.key < key || (e.key == key && e.depth < depth)
+}
+
+// NewLinearQuadTree builds a LinearQuadTree covering the supplied plane viewport.
+func NewLinearQuadTree[T geom.Numeric](
+	plane plane.Space2D[T],
+	opts ...LinearQuadTreeOption[T],
+) *LinearQuadTree[T] {
+	t := &LinearQuadTree[T]{
+		space:    plane,
+		viewport: plane.Viewport(),
+		maxDepth: LINEAR_MAX_DEPTH,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Add inserts item into the tree; returns false if it lies outside the viewport.
+func (t *LinearQuadTree[T]) Add(item Item[T]) bool {
+	entry, ok := t.entryOf(item)
+	if !ok {
+		return false
+	}
+	i := t.lowerBound(entry.key, entry.depth)
+	t.entries = slices.Insert(t.entries, i, entry)
+	return true
+}
+
+// Remove deletes item from the tree; returns false when nothing was removed.
+func (t *LinearQuadTree[T]) Remove(item Item[T]) bool {
+	entry, ok := t.entryOf(item)
+	if !ok {
+		return false
+	}
+	for i := t.lowerBound(entry.key, entry.depth); i < len(t.entries); i++ {
+		candidate := t.entries[i]
+		if candidate.key != entry.key || candidate.depth != entry.depth {
+			break
+		}
+		if candidate.item == item {
+			t.entries = slices.Delete(t.entries, i, i+1)
+			return true
+		}
+	}
+	return false
+}
+
+// Close releases internal resources held by the tree.
+func (t *LinearQuadTree[T]) Close() {
+	t.entries = nil
+}
+
+// Count returns the number of items stored in the tree.
+func (t *LinearQuadTree[T]) Count() int {
+	return len(t.entries)
+}
+
+// AllItems returns a snapshot of every stored item.
+func (t *LinearQuadTree[T]) AllItems() []Item[T] {
+	items := make([]Item[T], len(t.entries))
+	for i, entry := range t.entries {
+		items[i] = entry.item
+	}
+	sortItems(items)
+	return items
+}
+
+// Cells iterates over the stored items in Z-order together with their location
+// codes; the sequence is the tree's complete serialized form.
+func (t *LinearQuadTree[T]) Cells() iter.Seq2[LocationCode, Item[T]] {
+	return func(yield func(LocationCode, Item[T]) bool) {
+		for _, entry := range t.entries {
+			shift := 2 * (t.maxDepth - int(entry.depth))
+			if !yield(LocationCode{Code: entry.key >> shift, Depth: entry.depth}, entry.item) {
+				return
+			}
+		}
+	}
+}
+
+// LocationOf returns the cell item is, or would be, stored in.
+func (t *LinearQuadTree[T]) LocationOf(item Item[T]) (LocationCode, bool) {
+	entry, ok := t.entryOf(item)
+	if !ok {
+		return LocationCode{}, false
+	}
+	shift := 2 * (t.maxDepth - int(entry.depth))
+	return LocationCode{Code: entry.key >> shift, Depth: entry.depth}, true
+}
+
+// FindNeighbors retrieves items within margin of the target's bounds.
+func (t *LinearQuadTree[T]) FindNeighbors(target Item[T], margin T) []Item[T] {
+	probe := t.space.WrapAABB(target.Bound())
+	t.space.Expand(&probe, margin)
+	fragments := []geom.AABB[T]{probe.AABB}
+	probe.VisitFragments(func(_ plane.FragPosition, aabb geom.AABB[T]) bool {
+		fragments = append(fragments, aabb)
+		return true
+	})
+
+	boundingBoxDistance := t.space.AABBDistance()
+	neighbors := make([]Item[T], 0)
+	t.visitRange(fragments, func(item Item[T]) {
+		if item.SameID(target) {
+			return
+		}
+		if boundingBoxDistance(target.Bound(), item.Bound()) <= margin {
+			neighbors = append(neighbors, item)
+		}
+	})
+
+	sortItems(neighbors)
+	return neighbors
+}
+
+// BatchUpdate removes a batch of items and inserts the replacements with a
+// single re-sort. Setting triggerCompression also trims the backing storage.
+func (t *LinearQuadTree[T]) BatchUpdate(toRemove []Item[T], toAdd []Item[T], triggerCompression bool) {
+	if t == nil {
+		return
+	}
+
+	if len(toRemove) > 0 {
+		set := newBatchRemovalSet(toRemove)
+		t.entries = slices.DeleteFunc(t.entries, func(entry linearEntry[T]) bool {
+			return len(set.items) > 0 && set.consume(entry.item)
+		})
+	}
+
+	for _, item := range toAdd {
+		if entry, ok := t.entryOf(item); ok {
+			t.entries = append(t.entries, entry)
+		}
+	}
+	sort.SliceStable(t.entries, func(i, j int) bool {
+		return t.entries[i].before(t.entries[j].key, t.entries[j].depth)
+	})
+
+	if triggerCompression {
+		t.entries = slices.Clip(t.entries)
+	}
+}
+
+// visitRange calls fn for every item intersecting any of the fragments. The
+// implicit tree is descended cell by cell; a cell's items are found by binary
+// search, and cells whose key range holds no entries are never entered.
+func (t *LinearQuadTree[T]) visitRange(fragments []geom.AABB[T], fn func(Item[T])) {
+	var visit func(cx, cy uint32, depth int, lo, hi int)
+	visit = func(cx, cy uint32, depth int, lo, hi int) {
+		if lo == hi || !t.cellIntersectsAny(cx, cy, depth, fragments) {
+			return
+		}
+		shift := 2 * (t.maxDepth - depth)
+		start := morton.Encode64(cx, cy) << shift
+
+		i := lo
+		for ; i < hi && t.entries[i].key == start && int(t.entries[i].depth) == depth; i++ {
+			if intersectsAny(t.entries[i].item.Bound(), fragments) {
+				fn(t.entries[i].item)
+			}
+		}
+		if depth == t.maxDepth {
+			return
+		}
+
+		span := uint64(1) << shift / uint64(morton.QUADRANTS)
+		for q := range uint32(morton.QUADRANTS) {
+			end := start + uint64(q+1)*span
+			j := i + sort.Search(hi-i, func(k int) bool { return t.entries[i+k].key >= end })
+			visit(cx<<1|q&1, cy<<1|q>>1, depth+1, i, j)
+			i = j
+		}
+	}
+	visit(0, 0, 0, 0, len(t.entries))
+}
+
+func (t *LinearQuadTree[T]) cellIntersectsAny(cx, cy uint32, depth int, fragments []geom.AABB[T]) bool {
+	cells := float64(uint64(1) << depth)
+	width := (float64(t.viewport.BottomRight.X) - float64(t.viewport.TopLeft.X)) / cells
+	height := (float64(t.viewport.BottomRight.Y) - float64(t.viewport.TopLeft.Y)) / cells
+	minX := float64(t.viewport.TopLeft.X) + float64(cx)*width
+	minY := float64(t.viewport.TopLeft.Y) + float64(cy)*height
+	for _, fragment := range fragments {
+		if float64(fragment.TopLeft.X) <= minX+width && float64(fragment.BottomRight.X) >= minX &&
+			float64(fragment.TopLeft.Y) <= minY+height && float64(fragment.BottomRight.Y) >= minY {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *LinearQuadTree[T]) entryOf(item Item[T]) (linearEntry[T], bool) {
+	box := item.Bound()
+	if !t.viewport.Contains(box) {
+		return linearEntry[T]{}, false
+	}
+	x0, y0 := t.quantize(box.TopLeft)
+	x1, y1 := t.quantize(box.BottomRight)
+	depth := t.maxDepth - bits.Len32((x0^x1)|(y0^y1))
+	shift := t.maxDepth - depth
+	key := morton.Encode64(x0>>shift, y0>>shift) << (2 * shift)
+	return linearEntry[T]{key: key, depth: uint8(depth), item: item}, true
+}
+
+// quantize maps a point of the viewport onto the finest grid.
+func (t *LinearQuadTree[T]) quantize(vec geom.Vec[T]) (uint32, uint32) {
+	cells := float64(uint64(1) << t.maxDepth)
+	cell := func(v, lo, hi T) uint32 {
+		extent := float64(hi) - float64(lo)
+		if extent <= 0 {
+			return 0
+		}
+		c := math.Floor((float64(v) - float64(lo)) / extent * cells)
+		return uint32(min(max(c, 0), cells-1))
+	}
+	return cell(vec.X, t.viewport.TopLeft.X, t.viewport.BottomRight.X),
+		cell(vec.Y, t.viewport.TopLeft.Y, t.viewport.BottomRight.Y)
+}
+
+func (t *LinearQuadTree[T]) lowerBound(key uint64, depth uint8) int {
+	return sort.Search(len(t.entries), func(i int) bool {
+		return !t.entries[i].before(key, depth)
+	})
+}
@@ -0,0 +1,169 @@
+package qtree
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokg/pkg/plane"
+	"github.com/kjkrol/goku/pkg/sliceutils"
+)
+
+func TestLinearQuadTree_MatchesQuadTree(t *testing.T) {
+	for _, space := range []plane.Space2D[float64]{
+		plane.NewEuclidean2D(128.0, 128.0),
+		plane.NewToroidal2D(128.0, 128.0),
+	} {
+		t.Run(space.Name(), func(t *testing.T) {
+			qtree := NewQuadTree(space)
+			defer qtree.Close()
+			linear := NewLinearQuadTree(space)
+			defer linear.Close()
+
+			rnd := rand.New(rand.NewSource(33))
+			items := addRandomBoxes(qtree, rnd, 500)
+			for _, item := range items {
+				if !linear.Add(item) {
+					t.Fatalf("linear tree rejected %v", item.Bound())
+				}
+			}
+			for _, item := range items[:150] {
+				if !linear.Remove(item) || !qtree.Remove(item) {
+					t.Fatalf("failed to remove %v", item.Bound())
+				}
+			}
+			if linear.Count() != qtree.Count() {
+				t.Fatalf("count %d differs from quadtree count %d", linear.Count(), qtree.Count())
+			}
+
+			for i, target := range items[150:250] {
+				margin := float64(i % 25)
+				expected := qtree.FindNeighbors(target, margin)
+				got := linear.FindNeighbors(target, margin)
+				if !sliceutils.SameElements(got, expected) {
+					t.Errorf("FindNeighbors(%v, %v) = %v, expected %v", target, margin, got, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestLinearQuadTree_AddRemoveBounds(t *testing.T) {
+	linear := NewLinearQuadTree(plane.NewEuclidean2D(16, 16))
+	defer linear.Close()
+
+	if linear.Add(newTestItemFromPos(10, 10, 8, 8)) {
+		t.Errorf("expected item outside the viewport to be rejected")
+	}
+
+	item := newTestItemPointAtPos(3, 3)
+	linear.Add(item)
+	if linear.Remove(newTestItemPointAtPos(3, 3)) {
+		t.Errorf("expected a different item with the same bounds not to be removed")
+	}
+	if !linear.Remove(item) {
+		t.Errorf("expected item to be removed")
+	}
+	if linear.Remove(item) {
+		t.Errorf("expected second removal to fail")
+	}
+}
+
+func TestLinearQuadTree_LocationCodes(t *testing.T) {
+	linear := NewLinearQuadTree(plane.NewEuclidean2D(16, 16), WithLinearMaxDepth[int](2))
+	defer linear.Close()
+
+	testCases := []struct {
+		name     string
+		item     *TestItem[int]
+		expected LocationCode
+	}{
+		{name: "spansWholePlane", item: newTestItemFromPos(0, 0, 16, 16), expected: LocationCode{Code: 0, Depth: 0}},
+		{name: "straddlesCentre", item: newTestItemFromPos(6, 6, 4, 4), expected: LocationCode{Code: 0, Depth: 0}},
+		{name: "northEastQuadrant", item: newTestItemFromPos(9, 1, 6, 6), expected: LocationCode{Code: 1, Depth: 1}},
+		{name: "southWestQuadrant", item: newTestItemFromPos(1, 9, 6, 6), expected: LocationCode{Code: 2, Depth: 1}},
+		{name: "finestCell", item: newTestItemFromPos(13, 13, 1, 1), expected: LocationCode{Code: 15, Depth: 2}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := linear.LocationOf(tc.item)
+			if !ok || got != tc.expected {
+				t.Errorf("LocationOf(%v) = %+v, expected %+v", tc.item.Bound(), got, tc.expected)
+			}
+			linear.Add(tc.item)
+		})
+	}
+
+	previous := LocationCode{}
+	for code := range linear.Cells() {
+		shift := 2 * (2 - int(code.Depth))
+		if code.Code<<shift < previous.Code<<(2*(2-int(previous.Depth))) {
+			t.Errorf("cells out of Z-order: %+v after %+v", code, previous)
+		}
+		previous = code
+	}
+}
+
+func TestLinearQuadTree_BatchUpdate(t *testing.T) {
+	linear := NewLinearQuadTree(plane.NewEuclidean2D(64.0, 64.0))
+	defer linear.Close()
+
+	rnd := rand.New(rand.NewSource(34))
+	items := make([]Item[float64], 0, 100)
+	for range 100 {
+		item := newTestItemFromBox(geom.NewAABBAt(geom.NewVec(rnd.Float64()*60, rnd.Float64()*60), 2, 2))
+		linear.Add(item)
+		items = append(items, item)
+	}
+
+	toAdd := []Item[float64]{newTestItemPointAtPos(1.0, 1.0), newTestItemPointAtPos(63.0, 63.0)}
+	linear.BatchUpdate(items[:40], toAdd, true)
+
+	if linear.Count() != 62 {
+		t.Fatalf("expected 62 items after batch update, got %d", linear.Count())
+	}
+	for _, item := range items[:40] {
+		if linear.Remove(item) {
+			t.Errorf("expected %v to be removed by the batch", item.Bound())
+		}
+	}
+	for _, item := range append(items[40:], toAdd...) {
+		if !linear.Remove(item) {
+			t.Errorf("expected %v to be stored after the batch", item.Bound())
+		}
+	}
+}
+
+var linearBenchSink int
+
+func Benchmark_LinearQuadTree_vs_QuadTree_FindNeighbors(b *testing.B) {
+	space := plane.NewEuclidean2D(1024.0, 1024.0)
+	items := newSpriteItems(rand.New(rand.NewSource(4)), 10000, 1024)
+
+	qtree := NewQuadTree(space)
+	defer qtree.Close()
+	linear := NewLinearQuadTree(space)
+	defer linear.Close()
+	for _, item := range items {
+		qtree.Add(item)
+		linear.Add(item)
+	}
+
+	b.Run("pointer", func(b *testing.B) {
+		b.ReportAllocs()
+		i := 0
+		for b.Loop() {
+			linearBenchSink += len(qtree.FindNeighbors(items[i%len(items)], 8))
+			i++
+		}
+	})
+	b.Run("linear", func(b *testing.B) {
+		b.ReportAllocs()
+		i := 0
+		for b.Loop() {
+			linearBenchSink += len(linear.FindNeighbors(items[i%len(items)], 8))
+			i++
+		}
+	})
+}
@@ -0,0 +1,5 @@
+//go:build !race
+
+package qtree
+
+const raceEnabled = false
@@ -0,0 +1,380 @@
+package qtree
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokg/pkg/plane"
+	"github.com/kjkrol/gokq/pkg/dfs"
+)
+
+// PointEntry is a point stored in a PointTree together with its value.
+type PointEntry[T geom.Numeric, V comparable] struct {
+	Pos   geom.Vec[T]
+	Value V
+}
+
+// PointTree is a point-region quadtree for zero-size items. Coordinates are
+// stored inline next to their values, so there is no Bound() dispatch, and
+// every point descends into exactly one quadrant, so no item ever stays in an
+// internal node.
+type PointTree[T geom.Numeric, V comparable] struct {
+	space    plane.Space2D[T]
+	viewport geom.AABB[T]
+	cyclic   bool
+	capacity int
+	maxDepth int
+	count    int
+	root     *pointNode[T, V]
+}
+
+type PointTreeOption[T geom.Numeric, V comparable] func(*PointTree[T, V])
+
+func WithPointCapacity[T geom.Numeric, V comparable](capacity int) PointTreeOption[T, V] {
+	return func(t *PointTree[T, V]) {
+		if capacity > 0 {
+			t.capacity = capacity
+		}
+	}
+}
+
+func WithPointMaxDepth[T geom.Numeric, V comparable](depth int) PointTreeOption[T, V] {
+	return func(t *PointTree[T, V]) {
+		t.maxDepth = depth
+	}
+}
+
+// pointNode holds points while it is a leaf; its children are ordered like the
+// quadrants of geom.AABB.Split but always meet at center, so they cover the
+// node exactly even on integer planes with odd sizes.
+type pointNode[T geom.Numeric, V comparable] struct {
+	bounds geom.AABB[T]
+	center geom.Vec[T]
+	points []PointEntry[T, V]
+	childs *[4]pointNode[T, V]
+}
+
+// Children returns the quadrants of an internal node, or nil for a leaf.
+func (n *pointNode[T, V]) Children() []*pointNode[T, V] {
+	if n.childs == nil {
+		return nil
+	}
+	return []*pointNode[T, V]{&n.childs[0], &n.childs[1], &n.childs[2], &n.childs[3]}
+}
+
+// quadrantBounds returns the bounds of child q: the right and lower quadrants
+// run from center to the node's bottom-right corner.
+func (n *pointNode[T, V]) quadrantBounds(q int) geom.AABB[T] {
+	topLeft, bottomRight := n.bounds.TopLeft, n.center
+	if q&1 != 0 {
+		topLeft.X, bottomRight.X = n.center.X, n.bounds.BottomRight.X
+	}
+	if q&2 != 0 {
+		topLeft.Y, bottomRight.Y = n.center.Y, n.bounds.BottomRight.Y
+	}
+	return geom.NewAABB(topLeft, bottomRight)
+}
+
+func newPointNode[T geom.Numeric, V comparable](bounds geom.AABB[T]) pointNode[T, V] {
+	return pointNode[T, V]{
+		bounds: bounds,
+		center: geom.NewVec(
+			bounds.TopLeft.X+(bounds.BottomRight.X-bounds.TopLeft.X)/2,
+			bounds.TopLeft.Y+(bounds.BottomRight.Y-bounds.TopLeft.Y)/2,
+		),
+	}
+}
+
+// quadrant returns the child holding pos: points on a split line belong to
+// the right or lower quadrant.
+func (n *pointNode[T, V]) quadrant(pos geom.Vec[T]) int {
+	q := 0
+	if pos.X >= n.center.X {
+		q |= 1
+	}
+	if pos.Y >= n.center.Y {
+		q |= 2
+	}
+	return q
+}
+
+// NewPointTree builds a PointTree covering the supplied plane viewport.
+func NewPointTree[T geom.Numeric, V comparable](
+	plane plane.Space2D[T],
+	opts ...PointTreeOption[T, V],
+) *PointTree[T, V] {
+	root := newPointNode[T, V](plane.Viewport())
+	t := &PointTree[T, V]{
+		space:    plane,
+		viewport: plane.Viewport(),
+		cyclic:   IsCyclic(plane),
+		capacity: CAPACITY,
+		maxDepth: MAX_DEPTH,
+		root:     &root,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Add stores value at pos. On cyclic planes pos wraps around the edges;
+// elsewhere points outside the viewport are rejected.
+func (t *PointTree[T, V]) Add(pos geom.Vec[T], value V) bool {
+	pos, ok := t.normalize(pos)
+	if !ok {
+		return false
+	}
+	node, depth := t.root, 0
+	for node.childs != nil {
+		node, depth = &node.childs[node.quadrant(pos)], depth+1
+	}
+	node.points = append(node.points, PointEntry[T, V]{Pos: pos, Value: value})
+	t.count++
+	if len(node.points) > t.capacity && depth < t.maxDepth {
+		t.split(node)
+	}
+	return true
+}
+
+// Remove deletes the entry holding value at pos; returns false when nothing
+// was removed.
+func (t *PointTree[T, V]) Remove(pos geom.Vec[T], value V) bool {
+	pos, ok := t.normalize(pos)
+	if !ok {
+		return false
+	}
+	path := []*pointNode[T, V]{t.root}
+	node := t.root
+	for node.childs != nil {
+		node = &node.childs[node.quadrant(pos)]
+		path = append(path, node)
+	}
+	i := slices.Index(node.points, PointEntry[T, V]{Pos: pos, Value: value})
+	if i < 0 {
+		return false
+	}
+	node.points = slices.Delete(node.points, i, i+1)
+	t.count--
+
+	for i := len(path) - 2; i >= 0; i-- {
+		if !t.tryCollapse(path[i]) {
+			break
+		}
+	}
+	return true
+}
+
+// Len returns the number of stored points.
+func (t *PointTree[T, V]) Len() int {
+	return t.count
+}
+
+// Close releases internal resources held by the tree.
+func (t *PointTree[T, V]) Close() {
+	t.root.points = nil
+	t.root.childs = nil
+	t.count = 0
+}
+
+// FindInAABB returns the points inside area, ordered by position. On cyclic
+// planes the area wraps around the edges.
+func (t *PointTree[T, V]) FindInAABB(area geom.AABB[T]) []PointEntry[T, V] {
+	fragments := []geom.AABB[T]{area}
+	if t.cyclic {
+		wrapped := t.space.WrapAABB(area)
+		fragments[0] = wrapped.AABB
+		wrapped.VisitFragments(func(_ plane.FragPosition, aabb geom.AABB[T]) bool {
+			fragments = append(fragments, aabb)
+			return true
+		})
+	}
+
+	found := make([]PointEntry[T, V], 0)
+	t.visit(func(bounds geom.AABB[T]) bool {
+		return intersectsAny(bounds, fragments)
+	}, func(entry PointEntry[T, V]) {
+		for _, fragment := range fragments {
+			if fragment.IntersectsVec(entry.Pos) {
+				found = append(found, entry)
+				return
+			}
+		}
+	})
+
+	sortPointEntries(found)
+	return found
+}
+
+// FindInRadius returns the points within radius of center, ordered by
+// position. Distances follow the plane, so on cyclic planes they are measured
+// the shorter way around.
+func (t *PointTree[T, V]) FindInRadius(center geom.Vec[T], radius T) []PointEntry[T, V] {
+	center, _ = t.normalize(center)
+	limit := float64(radius) * float64(radius)
+
+	found := make([]PointEntry[T, V], 0)
+	t.visit(func(bounds geom.AABB[T]) bool {
+		return t.boxDistance2(center, bounds) <= limit
+	}, func(entry PointEntry[T, V]) {
+		if t.distance2(center, entry.Pos) <= limit {
+			found = append(found, entry)
+		}
+	})
+
+	sortPointEntries(found)
+	return found
+}
+
+// Nearest returns up to k points closest to center, nearest first. Nodes are
+// opened best-first, so only the nodes that may still hold a closer point are
+// visited.
+func (t *PointTree[T, V]) Nearest(center geom.Vec[T], k int) []PointEntry[T, V] {
+	if k <= 0 {
+		return []PointEntry[T, V]{}
+	}
+	center, _ = t.normalize(center)
+
+	type candidate struct {
+		entry PointEntry[T, V]
+		dist2 float64
+	}
+	best := make([]candidate, 0, k+1)
+	worst := func() float64 {
+		if len(best) < k {
+			return math.Inf(1)
+		}
+		return best[len(best)-1].dist2
+	}
+
+	dfs.BestFirst(t.root,
+		func(node *pointNode[T, V]) float64 { return t.boxDistance2(center, node.bounds) },
+		func(node *pointNode[T, V], dist2 float64) dfs.DFSControl {
+			if dist2 > worst() {
+				return dfs.Stop
+			}
+			for _, entry := range node.points {
+				d := t.distance2(center, entry.Pos)
+				if d > worst() {
+					continue
+				}
+				i, _ := slices.BinarySearchFunc(best, d, func(c candidate, d float64) int { return cmp.Compare(c.dist2, d) })
+				for i < len(best) && best[i].dist2 == d {
+					i++
+				}
+				best = slices.Insert(best, i, candidate{entry: entry, dist2: d})
+				if len(best) > k {
+					best = best[:k]
+				}
+			}
+			return dfs.Continue
+		},
+	)
+
+	nearest := make([]PointEntry[T, V], len(best))
+	for i, c := range best {
+		nearest[i] = c.entry
+	}
+	return nearest
+}
+
+func (t *PointTree[T, V]) split(node *pointNode[T, V]) {
+	node.childs = &[4]pointNode[T, V]{}
+	for q := range node.childs {
+		node.childs[q] = newPointNode[T, V](node.quadrantBounds(q))
+	}
+	for _, entry := range node.points {
+		child := &node.childs[node.quadrant(entry.Pos)]
+		child.points = append(child.points, entry)
+	}
+	node.points = nil
+}
+
+// tryCollapse turns node back into a leaf when its children are leaves holding
+// no more than capacity points in total.
+func (t *PointTree[T, V]) tryCollapse(node *pointNode[T, V]) bool {
+	total := 0
+	for i := range node.childs {
+		if node.childs[i].childs != nil {
+			return false
+		}
+		total += len(node.childs[i].points)
+	}
+	if total > t.capacity {
+		return false
+	}
+	points := make([]PointEntry[T, V], 0, total)
+	for i := range node.childs {
+		points = append(points, node.childs[i].points...)
+	}
+	node.points, node.childs = points, nil
+	return true
+}
+
+// visit walks the nodes accepted by enter and calls fn for the points of the
+// reached leaves.
+func (t *PointTree[T, V]) visit(enter func(geom.AABB[T]) bool, fn func(PointEntry[T, V])) {
+	stack := []*pointNode[T, V]{t.root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !enter(node.bounds) {
+			continue
+		}
+		if node.childs == nil {
+			for _, entry := range node.points {
+				fn(entry)
+			}
+			continue
+		}
+		for i := range node.childs {
+			stack = append(stack, &node.childs[i])
+		}
+	}
+}
+
+func (t *PointTree[T, V]) normalize(pos geom.Vec[T]) (geom.Vec[T], bool) {
+	if t.cyclic {
+		return t.space.WrapVec(pos).TopLeft, true
+	}
+	return pos, t.viewport.IntersectsVec(pos)
+}
+
+// distance2 returns the squared distance between two points of the plane.
+func (t *PointTree[T, V]) distance2(a, b geom.Vec[T]) float64 {
+	dx := t.axisGap(math.Abs(float64(a.X)-float64(b.X)), t.viewport.TopLeft.X, t.viewport.BottomRight.X)
+	dy := t.axisGap(math.Abs(float64(a.Y)-float64(b.Y)), t.viewport.TopLeft.Y, t.viewport.BottomRight.Y)
+	return dx*dx + dy*dy
+}
+
+// boxDistance2 returns the squared distance from p to the nearest point of box.
+func (t *PointTree[T, V]) boxDistance2(p geom.Vec[T], box geom.AABB[T]) float64 {
+	gap := func(v, lo, hi float64) float64 { return max(lo-v, v-hi, 0) }
+	axis := func(v, lo, hi, vlo, vhi T) float64 {
+		d := gap(float64(v), float64(lo), float64(hi))
+		if t.cyclic && d > 0 {
+			size := float64(vhi) - float64(vlo)
+			d = min(d, gap(float64(v)-size, float64(lo), float64(hi)), gap(float64(v)+size, float64(lo), float64(hi)))
+		}
+		return d
+	}
+	dx := axis(p.X, box.TopLeft.X, box.BottomRight.X, t.viewport.TopLeft.X, t.viewport.BottomRight.X)
+	dy := axis(p.Y, box.TopLeft.Y, box.BottomRight.Y, t.viewport.TopLeft.Y, t.viewport.BottomRight.Y)
+	return dx*dx + dy*dy
+}
+
+// axisGap folds a coordinate difference the shorter way around on cyclic planes.
+func (t *PointTree[T, V]) axisGap(d float64, lo, hi T) float64 {
+	if !t.cyclic {
+		return d
+	}
+	return min(d, float64(hi)-float64(lo)-d)
+}
+
+func sortPointEntries[T geom.Numeric, V comparable](entries []PointEntry[T, V]) {
+	slices.SortStableFunc(entries, func(a, b PointEntry[T, V]) int {
+		return cmp.Or(cmp.Compare(a.Pos.Y, b.Pos.Y), cmp.Compare(a.Pos.X, b.Pos.X))
+	})
+}
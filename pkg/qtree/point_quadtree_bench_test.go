@@ -0,0 +1,99 @@
+package qtree
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokg/pkg/plane"
+)
+
+var pointBenchSink int
+
+func newBenchPoints(n int) []geom.Vec[float64] {
+	rnd := rand.New(rand.NewSource(5))
+	points := make([]geom.Vec[float64], n)
+	for i := range points {
+		points[i] = geom.NewVec(rnd.Float64()*1024, rnd.Float64()*1024)
+	}
+	return points
+}
+
+func Benchmark_PointTree_vs_QuadTree_Add(b *testing.B) {
+	space := plane.NewToroidal2D(1024.0, 1024.0)
+	points := newBenchPoints(10000)
+	items := make([]*TestItem[float64], len(points))
+	for i, pos := range points {
+		items[i] = newTestItemFromVec(pos)
+	}
+
+	b.Run("quadtree", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			qtree := NewQuadTree(space)
+			for _, item := range items {
+				qtree.Add(item)
+			}
+			qtree.Close()
+		}
+	})
+	b.Run("pointtree", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			tree := NewPointTree[float64, int](space)
+			for i, pos := range points {
+				tree.Add(pos, i)
+			}
+			tree.Close()
+		}
+	})
+}
+
+func Benchmark_PointTree_vs_QuadTree_Radius(b *testing.B) {
+	space := plane.NewToroidal2D(1024.0, 1024.0)
+	points := newBenchPoints(10000)
+
+	qtree := NewQuadTree(space)
+	defer qtree.Close()
+	tree := NewPointTree[float64, int](space)
+	defer tree.Close()
+	items := make([]*TestItem[float64], len(points))
+	for i, pos := range points {
+		items[i] = newTestItemFromVec(pos)
+		qtree.Add(items[i])
+		tree.Add(pos, i)
+	}
+
+	b.Run("quadtree", func(b *testing.B) {
+		b.ReportAllocs()
+		i := 0
+		for b.Loop() {
+			pointBenchSink += len(qtree.FindNeighbors(items[i%len(items)], 16))
+			i++
+		}
+	})
+	b.Run("pointtree", func(b *testing.B) {
+		b.ReportAllocs()
+		i := 0
+		for b.Loop() {
+			pointBenchSink += len(tree.FindInRadius(points[i%len(points)], 16))
+			i++
+		}
+	})
+}
+
+func Benchmark_PointTree_Nearest(b *testing.B) {
+	points := newBenchPoints(10000)
+	tree := NewPointTree[float64, int](plane.NewToroidal2D(1024.0, 1024.0))
+	defer tree.Close()
+	for i, pos := range points {
+		tree.Add(pos, i)
+	}
+
+	b.ReportAllocs()
+	i := 0
+	for b.Loop() {
+		pointBenchSink += len(tree.Nearest(points[i%len(points)], 8))
+		i++
+	}
+}
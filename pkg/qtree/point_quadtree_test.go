@@ -0,0 +1,191 @@
+package qtree
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokg/pkg/plane"
+)
+
+func TestPointTree_MatchesBruteForce(t *testing.T) {
+	for _, space := range []plane.Space2D[float64]{
+		plane.NewEuclidean2D(100.0, 100.0),
+		plane.NewToroidal2D(100.0, 100.0),
+	} {
+		t.Run(space.Name(), func(t *testing.T) {
+			tree := NewPointTree[float64, int](space)
+			defer tree.Close()
+
+			rnd := rand.New(rand.NewSource(35))
+			points := make([]PointEntry[float64, int], 0, 1000)
+			for i := range 1000 {
+				entry := PointEntry[float64, int]{Pos: geom.NewVec(rnd.Float64()*100, rnd.Float64()*100), Value: i}
+				if !tree.Add(entry.Pos, entry.Value) {
+					t.Fatalf("failed to add %v", entry)
+				}
+				points = append(points, entry)
+			}
+			for _, entry := range points[:300] {
+				if !tree.Remove(entry.Pos, entry.Value) {
+					t.Fatalf("failed to remove %v", entry)
+				}
+			}
+			points = points[300:]
+			if tree.Len() != len(points) {
+				t.Fatalf("expected %d points, got %d", len(points), tree.Len())
+			}
+
+			cyclic := IsCyclic(space)
+			distance := func(a, b geom.Vec[float64]) float64 {
+				dx, dy := math.Abs(a.X-b.X), math.Abs(a.Y-b.Y)
+				if cyclic {
+					dx, dy = min(dx, 100-dx), min(dy, 100-dy)
+				}
+				return math.Hypot(dx, dy)
+			}
+
+			for range 100 {
+				center := geom.NewVec(rnd.Float64()*100, rnd.Float64()*100)
+				radius := rnd.Float64() * 15
+
+				expected := []PointEntry[float64, int]{}
+				for _, entry := range points {
+					if distance(center, entry.Pos) <= radius {
+						expected = append(expected, entry)
+					}
+				}
+				sortPointEntries(expected)
+				if got := tree.FindInRadius(center, radius); !slices.Equal(got, expected) {
+					t.Fatalf("FindInRadius(%v, %v) = %v, expected %v", center, radius, got, expected)
+				}
+
+				byDistance := slices.Clone(points)
+				slices.SortStableFunc(byDistance, func(a, b PointEntry[float64, int]) int {
+					return int(math.Copysign(1, distance(center, a.Pos)-distance(center, b.Pos)))
+				})
+				got := tree.Nearest(center, 5)
+				for i := range got {
+					if math.Abs(distance(center, got[i].Pos)-distance(center, byDistance[i].Pos)) > 1e-9 {
+						t.Fatalf("Nearest(%v)[%d] = %v, expected %v", center, i, got[i], byDistance[i])
+					}
+				}
+
+				area := geom.NewAABBAround(center, radius)
+				expectedInArea := []PointEntry[float64, int]{}
+				for _, entry := range points {
+					dx, dy := math.Abs(center.X-entry.Pos.X), math.Abs(center.Y-entry.Pos.Y)
+					if cyclic {
+						dx, dy = min(dx, 100-dx), min(dy, 100-dy)
+					}
+					if dx <= radius && dy <= radius {
+						expectedInArea = append(expectedInArea, entry)
+					}
+				}
+				sortPointEntries(expectedInArea)
+				if !cyclic {
+					expectedInArea = slices.DeleteFunc(expectedInArea, func(e PointEntry[float64, int]) bool {
+						return !area.IntersectsVec(e.Pos)
+					})
+				}
+				if got := tree.FindInAABB(area); !slices.Equal(got, expectedInArea) {
+					t.Fatalf("FindInAABB(%v) = %v, expected %v", area, got, expectedInArea)
+				}
+			}
+		})
+	}
+}
+
+func TestPointTree_AddRemove(t *testing.T) {
+	tree := NewPointTree[int, string](plane.NewEuclidean2D(16, 16), WithPointCapacity[int, string](2))
+	defer tree.Close()
+
+	if tree.Add(geom.NewVec(17, 3), "outside") {
+		t.Errorf("expected a point outside the viewport to be rejected")
+	}
+	for i, pos := range []geom.Vec[int]{{X: 1, Y: 1}, {X: 9, Y: 1}, {X: 8, Y: 8}, {X: 16, Y: 16}} {
+		tree.Add(pos, fmt.Sprint(i))
+	}
+	if tree.root.childs == nil {
+		t.Fatalf("expected the root to split")
+	}
+	if tree.Remove(geom.NewVec(1, 1), "1") {
+		t.Errorf("expected a different value at the same position not to be removed")
+	}
+	tree.Remove(geom.NewVec(1, 1), "0")
+	tree.Remove(geom.NewVec(9, 1), "1")
+	if tree.root.childs != nil {
+		t.Errorf("expected the root to collapse back into a leaf")
+	}
+	if got := tree.FindInAABB(tree.viewport); len(got) != 2 || tree.Len() != 2 {
+		t.Errorf("expected 2 remaining points, got %v", got)
+	}
+}
+
+func TestPointTree_OddIntegerViewport(t *testing.T) {
+	tree := NewPointTree[int, int](plane.NewEuclidean2D(101, 101))
+	defer tree.Close()
+
+	rnd := rand.New(rand.NewSource(135))
+	points := make([]PointEntry[int, int], 0, 600)
+	add := func(x, y int) {
+		entry := PointEntry[int, int]{Pos: geom.NewVec(x, y), Value: len(points)}
+		if !tree.Add(entry.Pos, entry.Value) {
+			t.Fatalf("failed to add %v", entry)
+		}
+		points = append(points, entry)
+	}
+	for y := 0; y <= 100; y += 10 {
+		add(100, y)
+		add(y, 100)
+	}
+	for range 500 {
+		add(rnd.Intn(101), rnd.Intn(101))
+	}
+
+	areas := []geom.AABB[int]{
+		geom.NewAABB(geom.NewVec(100, 0), geom.NewVec(100, 100)),
+		geom.NewAABB(geom.NewVec(0, 100), geom.NewVec(100, 100)),
+	}
+	for range 100 {
+		x, y := rnd.Intn(101), rnd.Intn(101)
+		areas = append(areas, geom.NewAABB(geom.NewVec(x, y), geom.NewVec(min(x+rnd.Intn(30), 100), min(y+rnd.Intn(30), 100))))
+	}
+	for _, area := range areas {
+		expected := []PointEntry[int, int]{}
+		for _, entry := range points {
+			if area.IntersectsVec(entry.Pos) {
+				expected = append(expected, entry)
+			}
+		}
+		sortPointEntries(expected)
+		if got := tree.FindInAABB(area); !slices.Equal(got, expected) {
+			t.Fatalf("FindInAABB(%v) returned %d points, expected %d", area, len(got), len(expected))
+		}
+	}
+	for _, entry := range points {
+		if got := tree.FindInRadius(entry.Pos, 0); !slices.Contains(got, entry) {
+			t.Fatalf("FindInRadius(%v, 0) = %v, expected it to hold %v", entry.Pos, got, entry)
+		}
+	}
+}
+
+func ExamplePointTree_Nearest() {
+	tree := NewPointTree[float64, string](plane.NewToroidal2D(100.0, 100.0))
+	defer tree.Close()
+
+	tree.Add(geom.NewVec(50.0, 50.0), "centre")
+	tree.Add(geom.NewVec(98.0, 1.0), "corner")
+	tree.Add(geom.NewVec(10.0, 0.0), "edge")
+
+	for _, entry := range tree.Nearest(geom.NewVec(1.0, 1.0), 2) {
+		fmt.Println(entry.Value)
+	}
+
+	// Output:
+	// corner
+	// edge
+}
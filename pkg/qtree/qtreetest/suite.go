@@ -0,0 +1,194 @@
+// Package qtreetest provides a conformance suite for qtree.SpatialIndex
+// implementations. Call Run from a test of the implementing package:
+//
+//	func TestGrid_Conformance(t *testing.T) {
+//		qtreetest.Run(t, func(space plane.Space2D[float64]) qtree.SpatialIndex[float64] {
+//			return grid.NewGrid(space, 8.0)
+//		})
+//	}
+package qtreetest
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokg/pkg/plane"
+	"github.com/kjkrol/gokq/pkg/qtree"
+	"github.com/kjkrol/goku/pkg/sliceutils"
+)
+
+// Factory builds an empty index covering the viewport of space.
+type Factory func(space plane.Space2D[float64]) qtree.SpatialIndex[float64]
+
+// Item is the item type stored by the suite. Items with equal IDs are the same
+// logical entity.
+type Item struct {
+	geom.AABB[float64]
+	ID int
+}
+
+func (it *Item) Bound() geom.AABB[float64] { return it.AABB }
+func (it *Item) SameID(other qtree.Item[float64]) bool {
+	o, ok := other.(*Item)
+	return ok && o.ID == it.ID
+}
+func (it *Item) String() string { return fmt.Sprintf("#%d%v", it.ID, it.AABB) }
+
+// NewItem returns an item with the w x h bounds at (x, y).
+func NewItem(id int, x, y, w, h float64) *Item {
+	return &Item{AABB: geom.NewAABBAt(geom.NewVec(x, y), w, h), ID: id}
+}
+
+// Run checks that indexes built by factory behave like qtree.QuadTree on
+// Euclidean and toroidal planes.
+func Run(t *testing.T, factory Factory) {
+	t.Helper()
+	for _, space := range []plane.Space2D[float64]{
+		plane.NewEuclidean2D(128.0, 128.0),
+		plane.NewToroidal2D(128.0, 128.0),
+	} {
+		t.Run(space.Name(), func(t *testing.T) {
+			t.Run("AddAndCount", func(t *testing.T) { testAddAndCount(t, space, factory) })
+			t.Run("RemoveByIdentity", func(t *testing.T) { testRemoveByIdentity(t, space, factory) })
+			t.Run("FindNeighbors", func(t *testing.T) { testFindNeighbors(t, space, factory) })
+			t.Run("BatchUpdate", func(t *testing.T) { testBatchUpdate(t, space, factory) })
+		})
+	}
+}
+
+func randomItems(seed int64, n int) []qtree.Item[float64] {
+	rnd := rand.New(rand.NewSource(seed))
+	items := make([]qtree.Item[float64], n)
+	for i := range items {
+		w, h := rnd.Float64()*10, rnd.Float64()*10
+		if i%5 == 0 {
+			w, h = 0, 0
+		}
+		items[i] = NewItem(i, rnd.Float64()*(128-w), rnd.Float64()*(128-h), w, h)
+	}
+	return items
+}
+
+func addAll(t *testing.T, index qtree.SpatialIndex[float64], items []qtree.Item[float64]) {
+	t.Helper()
+	for _, item := range items {
+		if !index.Add(item) {
+			t.Fatalf("Add(%v) = false, expected the item to be stored", item)
+		}
+	}
+}
+
+func expectItems(t *testing.T, index qtree.SpatialIndex[float64], expected []qtree.Item[float64]) {
+	t.Helper()
+	if index.Count() != len(expected) {
+		t.Fatalf("Count() = %d, expected %d", index.Count(), len(expected))
+	}
+	got := index.AllItems()
+	if !sliceutils.SameElements(got, expected) {
+		t.Fatalf("AllItems() = %v, expected %v", got, expected)
+	}
+	sorted := slices.Clone(got)
+	qtree.SortItems(sorted)
+	if !slices.Equal(got, sorted) {
+		t.Errorf("AllItems() is not ordered as qtree.SortItems")
+	}
+}
+
+func testAddAndCount(t *testing.T, space plane.Space2D[float64], factory Factory) {
+	index := factory(space)
+	defer index.Close()
+
+	expectItems(t, index, nil)
+	items := randomItems(1, 300)
+	addAll(t, index, items)
+	expectItems(t, index, items)
+
+	if index.Add(NewItem(-1, 120, 120, 16, 16)) {
+		t.Errorf("expected an item outside the viewport to be rejected")
+	}
+	if index.Count() != len(items) {
+		t.Errorf("a rejected item changed Count() to %d", index.Count())
+	}
+}
+
+func testRemoveByIdentity(t *testing.T, space plane.Space2D[float64], factory Factory) {
+	index := factory(space)
+	defer index.Close()
+
+	items := randomItems(2, 200)
+	addAll(t, index, items)
+
+	twin := NewItem(1000, 0, 0, 0, 0)
+	twin.AABB = items[0].Bound()
+	if index.Remove(twin) {
+		t.Errorf("expected a distinct item with the same bounds not to be removed")
+	}
+	for _, item := range items[:100] {
+		if !index.Remove(item) {
+			t.Fatalf("Remove(%v) = false, expected the item to be removed", item)
+		}
+	}
+	if index.Remove(items[0]) {
+		t.Errorf("expected the second removal of %v to fail", items[0])
+	}
+	expectItems(t, index, items[100:])
+}
+
+func testFindNeighbors(t *testing.T, space plane.Space2D[float64], factory Factory) {
+	index := factory(space)
+	defer index.Close()
+
+	items := randomItems(3, 400)
+	addAll(t, index, items)
+
+	distance := space.AABBDistance()
+	for i, target := range items[:100] {
+		margin := float64(i % 16)
+		expected := []qtree.Item[float64]{}
+		for _, item := range items {
+			if !item.SameID(target) && distance(target.Bound(), item.Bound()) <= margin {
+				expected = append(expected, item)
+			}
+		}
+		qtree.SortItems(expected)
+
+		got := index.FindNeighbors(target, margin)
+		if !sliceutils.SameElements(got, expected) {
+			t.Fatalf("FindNeighbors(%v, %v) = %v, expected %v", target, margin, got, expected)
+		}
+		sorted := slices.Clone(got)
+		qtree.SortItems(sorted)
+		if !slices.Equal(got, sorted) {
+			t.Fatalf("FindNeighbors(%v, %v) is not ordered as qtree.SortItems", target, margin)
+		}
+	}
+}
+
+func testBatchUpdate(t *testing.T, space plane.Space2D[float64], factory Factory) {
+	index := factory(space)
+	defer index.Close()
+
+	items := randomItems(4, 200)
+	addAll(t, index, items)
+
+	replacements := randomItems(5, 50)
+	for i, item := range replacements {
+		item.(*Item).ID = 1000 + i
+	}
+	index.BatchUpdate(items[:80], replacements, false)
+	expected := append(slices.Clone(items[80:]), replacements...)
+	expectItems(t, index, expected)
+
+	index.BatchUpdate(replacements[:25], nil, true)
+	expectItems(t, index, append(slices.Clone(items[80:]), replacements[25:]...))
+
+	for _, item := range replacements[25:] {
+		if !index.Remove(item) {
+			t.Fatalf("Remove(%v) = false after the batch update", item)
+		}
+	}
+	expectItems(t, index, items[80:])
+}
@@ -1,6 +1,11 @@
 package qtree
 
 import (
+	"cmp"
+	"context"
+	"math"
+	"slices"
+
 	"github.com/kjkrol/gokg/pkg/geom"
 	"github.com/kjkrol/gokg/pkg/plane"
 	"github.com/kjkrol/gokq/pkg/dfs"
@@ -20,11 +25,21 @@ type Item[T geom.Numeric] interface {
 
 // QuadTree stores spatial items in a hierarchical grid for fast range queries.
 type QuadTree[T geom.Numeric] struct {
-	root        *Node[T]
-	appender    QuadTreeAppender[T]
-	remover     QuadTreeRemover[T]
-	finder      QuadTreeFinder[T]
-	coordinator BatchUpdateCoordinator[T]
+	root              *Node[T]
+	space             plane.Space2D[T]
+	hilbert           bool
+	parallelThreshold int
+	appender          QuadTreeAppender[T]
+	remover           QuadTreeRemover[T]
+	finder            QuadTreeFinder[T]
+	counter           QuadTreeCounter[T]
+	coordinator       BatchUpdateCoordinator[T]
+	traversers        *dfs.TraverserPool[*Node[T], struct{}]
+	depths            *dfs.TraverserPool[*Node[T], int]
+	boundsPolicy      OutOfBoundsPolicy
+	placed            map[Item[T]][]*placement[T]
+	overflow          []Item[T]
+	fragments         int
 }
 
 // NewQuadTree builds a QuadTree covering the supplied plane viewport.
@@ -35,65 +50,262 @@ func NewQuadTree[T geom.Numeric](
 	rootBounds := plane.Viewport()
 	root := newNode(rootBounds, nil)
 	finderStrategy := NewDefaultQuadTreeFinderStrategy(plane)
+	traversers := dfs.NewTraverserPool[*Node[T], struct{}]()
 	qt := &QuadTree[T]{
-		root:     root,
-		appender: QuadTreeAppender[T]{maxDepth: MAX_DEPTH, capacity: CAPACITY},
-		remover:  QuadTreeRemover[T]{capacity: CAPACITY},
-		finder:   NewQuadTreeFinder(finderStrategy),
+		root:              root,
+		space:             plane,
+		parallelThreshold: PARALLEL_THRESHOLD,
+		appender:          QuadTreeAppender[T]{maxDepth: MAX_DEPTH, capacity: CAPACITY},
+		remover:           QuadTreeRemover[T]{capacity: CAPACITY, traversers: traversers},
+		finder:            NewQuadTreeFinder(finderStrategy),
+		counter:           NewQuadTreeCounter(plane, finderStrategy),
+		traversers:        traversers,
+		depths:            dfs.NewTraverserPool[*Node[T], int](),
 	}
+	qt.finder.traversers = traversers
+	qt.counter.traversers = traversers
 	qt.coordinator = NewBatchUpdateCoordinator(qt.appender, qt.remover)
 	for _, opt := range opts {
 		opt(qt)
 	}
+	qt.coordinator.QuadTreeAppender = qt.appender
+	qt.coordinator.QuadTreeRemover = qt.remover
+	refreshSummary(qt.appender.aggregator, root)
 	return qt
 }
 
-// Add inserts item into the tree; returns false if it cannot be placed.
+// Add inserts item into the tree; returns false if it cannot be placed. Items
+// reaching outside the viewport are handled by the OutOfBoundsPolicy.
 func (t *QuadTree[T]) Add(item Item[T]) bool {
-	return t.appender.add(t.root, item, 0)
+	if t.appender.add(t.root, item, 0) {
+		return true
+	}
+	return t.addOutOfBounds(item)
+}
+
+// AddAll bulk-loads items and returns how many of them were placed. With
+// WithHilbertOrder the items are inserted along the Hilbert curve, so
+// consecutive insertions land in neighbouring nodes.
+func (t *QuadTree[T]) AddAll(items []Item[T]) int {
+	if t.hilbert {
+		items = slices.Clone(items)
+		sortItemsByHilbert(t.space, items)
+	}
+	added := 0
+	for _, item := range items {
+		if t.Add(item) {
+			added++
+		}
+	}
+	return added
 }
 
 // Remove deletes item from the tree; returns false when nothing was removed.
 func (t *QuadTree[T]) Remove(item Item[T]) bool {
-	return t.remover.remove(t.root, item)
+	if t.remover.remove(t.root, item) {
+		return true
+	}
+	return t.removeOutOfBounds(item)
 }
 
 // Close releases internal resources held by the tree.
 func (t *QuadTree[T]) Close() {
 	t.root.close()
 	t.coordinator.Close()
+	t.placed, t.overflow, t.fragments = nil, nil, 0
 }
 
 // Count returns the number of items stored in the tree.
 func (t *QuadTree[T]) Count() int {
-	total := 0
-
-	dfs.DFS(t.root, struct{}{}, func(node *Node[T], _ struct{}) (dfs.DFSControl, struct{}) {
-		total += len(node.items)
-		return dfs.DFSControl{}, struct{}{}
-	})
-
-	return total
+	return t.root.size - t.fragments + len(t.overflow)
 }
 
 // Depth reports the maximum depth for active nodes.
 func (t *QuadTree[T]) Depth() int {
-	return t.root.depth()
+	return t.root.depth(t.depths)
 }
 
-// AllItems returns a snapshot of every stored item.
+// AllItems returns a snapshot of every stored item, ordered by position or,
+// with WithHilbertOrder, along the Hilbert curve through the item centres.
 func (t *QuadTree[T]) AllItems() []Item[T] {
-	return t.root.allItems()
+	var items []Item[T]
+	if t.parallel() {
+		items = t.parallelItems()
+	} else {
+		items = t.root.allItems()
+	}
+	items = t.resolve(items, func(Item[T]) bool { return true })
+	if t.hilbert {
+		sortItemsByHilbert(t.space, items)
+	}
+	return items
 }
 
-// LeafBounds returns the bounding boxes of all current leaf nodes.
+// AllItemsCtx is AllItems that gives up with ctx.Err() once ctx is done.
+func (t *QuadTree[T]) AllItemsCtx(ctx context.Context) ([]Item[T], error) {
+	items, err := t.root.allItemsCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items = t.resolve(items, func(Item[T]) bool { return true })
+	if t.hilbert {
+		sortItemsByHilbert(t.space, items)
+	}
+	return items, nil
+}
+
+// LeafBounds returns the bounding boxes of all current leaf nodes in
+// depth-first order, visiting the children of a node in the quadrant order
+// NW, NE, SW, SE.
 func (t *QuadTree[T]) LeafBounds() []geom.AABB[T] {
 	return t.root.leafBounds()
 }
 
+// VisitNodes walks the tree depth-first and calls fn with a read-only view of
+// every node; returning false from fn skips that node's descendants.
+func (t *QuadTree[T]) VisitNodes(fn func(node NodeView[T]) bool) {
+	t.traversers.DFS(t.root, struct{}{}, func(node *Node[T], _ struct{}) (dfs.DFSControl, struct{}) {
+		if !fn(NodeView[T]{node: node}) {
+			return dfs.SkipChildren, struct{}{}
+		}
+		return dfs.Continue, struct{}{}
+	})
+}
+
 // FindNeighbors retrieves items within margin of the target's bounds.
 func (t *QuadTree[T]) FindNeighbors(target Item[T], margin T) []Item[T] {
-	return t.finder.FindNeighbors(t.root, target, margin)
+	return t.resolve(t.finder.FindNeighbors(t.root, target, margin), t.neighborMatch(target, margin))
+}
+
+// FindNeighborsCtx is FindNeighbors that gives up with ctx.Err() once ctx is
+// done, so request deadlines propagate into large lookups.
+func (t *QuadTree[T]) FindNeighborsCtx(ctx context.Context, target Item[T], margin T) ([]Item[T], error) {
+	found, err := t.finder.FindNeighborsCtx(ctx, t.root, target, margin)
+	if err != nil {
+		return nil, err
+	}
+	return t.resolve(found, t.neighborMatch(target, margin)), nil
+}
+
+// FindInAABB returns the items intersecting area, ordered by position. On cyclic
+// planes the area wraps around the edges.
+func (t *QuadTree[T]) FindInAABB(area geom.AABB[T]) []Item[T] {
+	fragments := areaFragments(t.space, area)
+	return t.resolve(t.finder.FindInAABB(t.root, fragments), areaMatch(fragments))
+}
+
+// FindInAABBCtx is FindInAABB that gives up with ctx.Err() once ctx is done.
+func (t *QuadTree[T]) FindInAABBCtx(ctx context.Context, area geom.AABB[T]) ([]Item[T], error) {
+	fragments := areaFragments(t.space, area)
+	found, err := t.finder.FindInAABBCtx(ctx, t.root, fragments)
+	if err != nil {
+		return nil, err
+	}
+	return t.resolve(found, areaMatch(fragments)), nil
+}
+
+// CountInAABB returns the number of items intersecting area. On cyclic planes
+// the area wraps around the edges.
+func (t *QuadTree[T]) CountInAABB(area geom.AABB[T]) int {
+	if !t.hasOutOfBounds() {
+		return t.counter.CountInAABB(t.root, area)
+	}
+	tally := t.newFragmentTally(nil)
+	count := t.counter.countInAABB(t.root, area, tally) - tally.repeated()
+	return count + t.countOverflow(areaMatch(areaFragments(t.space, area)))
+}
+
+// CountNeighbors returns the number of items FindNeighbors would report for the
+// same target and margin, without collecting or sorting them.
+func (t *QuadTree[T]) CountNeighbors(target Item[T], margin T) int {
+	if !t.hasOutOfBounds() {
+		return t.counter.CountNeighbors(t.root, target, margin)
+	}
+	tally := t.newFragmentTally(target)
+	count := t.counter.countNeighbors(t.root, target, margin, t.placed[target], tally) - tally.repeated()
+	return count + t.countOverflow(t.neighborMatch(target, margin))
+}
+
+// Nearest returns up to k items whose bounds lie closest to pos, nearest first.
+// Nodes are opened best-first by the distance to their bounds, so only the
+// nodes that may still hold a closer item are visited. On cyclic planes the
+// distance is taken the shorter way around the edges.
+func (t *QuadTree[T]) Nearest(pos geom.Vec[T], k int) []Item[T] {
+	if k <= 0 {
+		return []Item[T]{}
+	}
+
+	type candidate struct {
+		item Item[T]
+		dist float64
+	}
+	best := make([]candidate, 0, k+1)
+	worst := func() float64 {
+		if len(best) < k {
+			return math.Inf(1)
+		}
+		return best[len(best)-1].dist
+	}
+	offer := func(entry Item[T]) {
+		d := PointDistance(t.space, pos, entry.Bound())
+		if d > worst() {
+			return
+		}
+		item := original(entry)
+		if j := slices.IndexFunc(best, func(c candidate) bool { return c.item == item }); j >= 0 {
+			if best[j].dist <= d {
+				return
+			}
+			best = slices.Delete(best, j, j+1)
+		}
+		i, _ := slices.BinarySearchFunc(best, d, func(c candidate, d float64) int { return cmp.Compare(c.dist, d) })
+		for i < len(best) && best[i].dist == d {
+			i++
+		}
+		best = slices.Insert(best, i, candidate{item: item, dist: d})
+		if len(best) > k {
+			best = best[:k]
+		}
+	}
+
+	for _, item := range t.overflow {
+		offer(item)
+	}
+	dfs.BestFirst(t.root,
+		func(node *Node[T]) float64 { return PointDistance(t.space, pos, node.loose) },
+		func(node *Node[T], dist float64) dfs.DFSControl {
+			if dist > worst() {
+				return dfs.Stop
+			}
+			for _, entry := range node.items {
+				offer(entry)
+			}
+			return dfs.Continue
+		},
+	)
+
+	nearest := make([]Item[T], len(best))
+	for i, c := range best {
+		nearest[i] = c.item
+	}
+	return nearest
+}
+
+// neighborMatch reports whether an item kept outside the tree, or a stored
+// placement, is within margin of target.
+func (t *QuadTree[T]) neighborMatch(target Item[T], margin T) func(Item[T]) bool {
+	distance := wrappedDistance(t.space)
+	return func(item Item[T]) bool {
+		return !item.SameID(target) && distance(target.Bound(), item.Bound()) <= margin
+	}
+}
+
+// areaMatch reports whether an item kept outside the tree, or a stored
+// placement, intersects any of the fragments of a query area.
+func areaMatch[T geom.Numeric](fragments []geom.AABB[T]) func(Item[T]) bool {
+	return func(item Item[T]) bool {
+		return intersectsAny(item.Bound(), fragments)
+	}
 }
 
 // BatchUpdate removes a batch of items, re-inserts the supplied replacements and
@@ -105,5 +317,24 @@ func (t *QuadTree[T]) BatchUpdate(toRemove []Item[T], toAdd []Item[T], triggerCo
 		return
 	}
 
+	if t.hasOutOfBounds() {
+		toRemove = t.takeRemovals(toRemove)
+	}
+	var outside []Item[T]
+	if t.boundsPolicy != RejectOutOfBounds {
+		inside := make([]Item[T], 0, len(toAdd))
+		for _, item := range toAdd {
+			if t.root.loose.Contains(item.Bound()) {
+				inside = append(inside, item)
+			} else {
+				outside = append(outside, item)
+			}
+		}
+		toAdd = inside
+	}
+
 	t.coordinator.BatchUpdate(t.root, toRemove, toAdd, triggerCompression)
+	for _, item := range outside {
+		t.addOutOfBounds(item)
+	}
 }
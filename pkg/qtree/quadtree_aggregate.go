@@ -0,0 +1,99 @@
+package qtree
+
+import (
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokq/pkg/dfs"
+)
+
+// Monoid folds items into a summary of type A. Combine must be associative and
+// Identity must be its neutral element, so partial results can be merged in any
+// grouping.
+type Monoid[T geom.Numeric, A any] struct {
+	Identity A
+	Lift     func(Item[T]) A
+	Combine  func(A, A) A
+}
+
+// Aggregate folds monoid over every item intersecting area without building an
+// intermediate slice. Subtrees fully covered by area are folded without any
+// per-item intersection tests, but every matching item is still lifted, so the
+// cost grows with the number of items in area; SummaryInAABB merges the
+// summaries cached per node instead. Trees holding items placed by the
+// OutOfBoundsPolicy fold the result of FindInAABB instead, so every item is
+// lifted once.
+func Aggregate[T geom.Numeric, A any](t *QuadTree[T], area geom.AABB[T], monoid Monoid[T, A]) A {
+	return aggregateInAABB(t, area, monoid, func(node *Node[T]) A {
+		return foldSubtree(t.traversers, node, monoid)
+	})
+}
+
+// SummaryInAABB merges the summaries the Aggregator passed to WithAggregator
+// keeps for the items intersecting area. Nodes fully covered by area contribute
+// their cached summary, so only the nodes crossing the edge of area are visited
+// item by item. The second result is false when the tree was built without an
+// aggregator of type A.
+func SummaryInAABB[T geom.Numeric, A any](t *QuadTree[T], area geom.AABB[T]) (A, bool) {
+	typed, ok := t.appender.aggregator.(typedAggregator[T, A])
+	if !ok {
+		var zero A
+		return zero, false
+	}
+	monoid := Monoid[T, A]{Identity: typed.Empty(), Lift: typed.Lift, Combine: typed.Merge}
+	return aggregateInAABB(t, area, monoid, func(node *Node[T]) A {
+		if summary, ok := NodeSummary[T, A](node); ok {
+			return summary
+		}
+		return foldSubtree(t.traversers, node, monoid)
+	}), true
+}
+
+// aggregateInAABB folds monoid over the items intersecting area, taking the
+// contribution of every node fully covered by area from covered.
+func aggregateInAABB[T geom.Numeric, A any](
+	t *QuadTree[T],
+	area geom.AABB[T],
+	monoid Monoid[T, A],
+	covered func(node *Node[T]) A,
+) A {
+	acc := monoid.Identity
+	if t.hasOutOfBounds() {
+		for _, item := range t.FindInAABB(area) {
+			acc = monoid.Combine(acc, monoid.Lift(item))
+		}
+		return acc
+	}
+	fragments := areaFragments(t.space, area)
+
+	t.traversers.DFS(t.root, struct{}{}, func(node *Node[T], _ struct{}) (dfs.DFSControl, struct{}) {
+		if !intersectsAny(node.loose, fragments) {
+			return dfs.SkipChildren, struct{}{}
+		}
+		if coveredByAny(node.loose, fragments) {
+			acc = monoid.Combine(acc, covered(node))
+			return dfs.SkipChildren, struct{}{}
+		}
+		for _, item := range node.items {
+			if intersectsAny(item.Bound(), fragments) {
+				acc = monoid.Combine(acc, monoid.Lift(item))
+			}
+		}
+		return dfs.Continue, struct{}{}
+	})
+
+	return acc
+}
+
+func foldSubtree[T geom.Numeric, A any](
+	traversers *dfs.TraverserPool[*Node[T], struct{}],
+	root *Node[T],
+	monoid Monoid[T, A],
+) A {
+	acc := monoid.Identity
+	traversers.DFS(root, struct{}{}, func(node *Node[T], _ struct{}) (dfs.DFSControl, struct{}) {
+		for _, item := range node.items {
+			acc = monoid.Combine(acc, monoid.Lift(item))
+		}
+		return dfs.Continue, struct{}{}
+	})
+	return acc
+}
@@ -0,0 +1,66 @@
+package qtree
+
+import "github.com/kjkrol/gokg/pkg/geom"
+
+// Aggregator describes a per-node summary kept up to date on every mutation.
+// A node's summary covers the items stored in the node and all its descendants,
+// so Merge must be associative and Empty must be its neutral element. An item
+// wrapped into several fragments is lifted only by the node holding its first
+// fragment, and items in the overflow bucket belong to no node.
+type Aggregator[T geom.Numeric, A any] interface {
+	Empty() A
+	Lift(item Item[T]) A
+	Merge(a, b A) A
+}
+
+// nodeAggregator is the type-erased form of Aggregator stored by the tree
+// components, which are not parameterized by the summary type.
+type nodeAggregator[T geom.Numeric] interface {
+	summarize(node *Node[T]) any
+}
+
+type typedAggregator[T geom.Numeric, A any] struct {
+	Aggregator[T, A]
+}
+
+func (ta typedAggregator[T, A]) summarize(node *Node[T]) any {
+	acc := ta.Empty()
+	for _, item := range node.items {
+		if !duplicateEntry(item) {
+			acc = ta.Merge(acc, ta.Lift(original(item)))
+		}
+	}
+	for _, child := range node.childs {
+		if summary, ok := child.summary.(A); ok {
+			acc = ta.Merge(acc, summary)
+		}
+	}
+	return acc
+}
+
+// NodeSummary returns the summary maintained for node by the Aggregator passed
+// to WithAggregator. The second result is false when the tree was built without
+// an aggregator of type A.
+func NodeSummary[T geom.Numeric, A any](node *Node[T]) (A, bool) {
+	summary, ok := node.summary.(A)
+	return summary, ok
+}
+
+// refreshSummary recomputes the summary of a single node from its items and the
+// already up-to-date summaries of its children.
+func refreshSummary[T geom.Numeric](aggregator nodeAggregator[T], node *Node[T]) {
+	if aggregator == nil {
+		return
+	}
+	node.summary = aggregator.summarize(node)
+}
+
+// refreshSummaryPath recomputes summaries from node up to the root.
+func refreshSummaryPath[T geom.Numeric](aggregator nodeAggregator[T], node *Node[T]) {
+	if aggregator == nil {
+		return
+	}
+	for n := node; n != nil; n = n.parent {
+		n.summary = aggregator.summarize(n)
+	}
+}
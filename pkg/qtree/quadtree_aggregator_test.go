@@ -0,0 +1,172 @@
+package qtree
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokg/pkg/plane"
+)
+
+type massSummary struct {
+	mass     float64
+	weighted geom.Vec[float64]
+	maxArea  float64
+}
+
+type massAggregator struct{}
+
+func (massAggregator) Empty() massSummary { return massSummary{} }
+
+func (massAggregator) Lift(item Item[float64]) massSummary {
+	box := item.Bound()
+	center := geom.NewVec(
+		(box.TopLeft.X+box.BottomRight.X)/2,
+		(box.TopLeft.Y+box.BottomRight.Y)/2,
+	)
+	return massSummary{mass: 1, weighted: center, maxArea: boxArea(box)}
+}
+
+func (massAggregator) Merge(a, b massSummary) massSummary {
+	return massSummary{
+		mass:     a.mass + b.mass,
+		weighted: a.weighted.Add(b.weighted),
+		maxArea:  max(a.maxArea, b.maxArea),
+	}
+}
+
+func TestQuadTree_AggregatorFollowsMutations(t *testing.T) {
+	qtree := NewQuadTree(plane.NewEuclidean2D(64.0, 64.0), WithAggregator[float64, massSummary](massAggregator{}))
+	defer qtree.Close()
+
+	rnd := rand.New(rand.NewSource(30))
+	items := addRandomBoxes(qtree, rnd, 200)
+	verifySummaries(t, qtree)
+
+	for _, item := range items[:70] {
+		qtree.Remove(item)
+	}
+	verifySummaries(t, qtree)
+
+	toRemove := make([]Item[float64], 0, 70)
+	for _, item := range items[70:140] {
+		toRemove = append(toRemove, item)
+	}
+	toAdd := []Item[float64]{newTestItemPointAtPos(3.0, 3.0), newTestItemPointAtPos(50.0, 20.0)}
+	qtree.BatchUpdate(toRemove, toAdd, false)
+	verifySummaries(t, qtree)
+
+	qtree.BatchUpdate(nil, nil, true)
+	verifySummaries(t, qtree)
+}
+
+// liftCounter counts items and the number of Lift calls made on it.
+type liftCounter struct{ lifts *int }
+
+func (liftCounter) Empty() int { return 0 }
+
+func (c liftCounter) Lift(Item[float64]) int {
+	*c.lifts++
+	return 1
+}
+
+func (liftCounter) Merge(a, b int) int { return a + b }
+
+func TestSummaryInAABB_MergesCachedSummaries(t *testing.T) {
+	lifts := 0
+	qtree := NewQuadTree(plane.NewEuclidean2D(64.0, 64.0), WithAggregator[float64, int](liftCounter{&lifts}))
+	defer qtree.Close()
+
+	rnd := rand.New(rand.NewSource(26))
+	items := addRandomBoxes(qtree, rnd, 300)
+
+	for range 50 {
+		area := geom.NewAABBAt(geom.NewVec(rnd.Float64()*48, rnd.Float64()*48), rnd.Float64()*32, rnd.Float64()*32)
+		expected := 0
+		for _, item := range items {
+			if area.Intersects(item.Bound()) {
+				expected++
+			}
+		}
+		if got, ok := SummaryInAABB[float64, int](qtree, area); !ok || got != expected {
+			t.Fatalf("SummaryInAABB(%v) = %d, %v, expected %d", area, got, ok, expected)
+		}
+	}
+
+	lifts = 0
+	if got, _ := SummaryInAABB[float64, int](qtree, qtree.root.bounds); got != len(items) || lifts != 0 {
+		t.Errorf("SummaryInAABB(viewport) = %d after %d lifts, expected %d from the root summary", got, lifts, len(items))
+	}
+	if _, ok := SummaryInAABB[float64, massSummary](qtree, qtree.root.bounds); ok {
+		t.Error("SummaryInAABB reported a summary type the tree does not maintain")
+	}
+}
+
+func TestQuadTree_VisitNodesSkipsChildren(t *testing.T) {
+	qtree := NewQuadTree(plane.NewEuclidean2D(64.0, 64.0))
+	defer qtree.Close()
+	addRandomBoxes(qtree, rand.New(rand.NewSource(31)), 50)
+
+	visited := 0
+	qtree.VisitNodes(func(NodeView[float64]) bool {
+		visited++
+		return false
+	})
+	if visited != 1 {
+		t.Errorf("expected only the root to be visited, got %d nodes", visited)
+	}
+
+	if _, ok := NodeSummary[float64, massSummary](qtree.root); ok {
+		t.Errorf("expected no summary without an aggregator")
+	}
+}
+
+func verifySummaries(t *testing.T, qtree *QuadTree[float64]) {
+	t.Helper()
+	qtree.VisitNodes(func(view NodeView[float64]) bool {
+		node := view.node
+		summary, ok := NodeSummary[float64, massSummary](node)
+		if !ok {
+			t.Fatalf("node %v has no summary", node.Bounds())
+		}
+		expected := massSummary{}
+		for _, item := range node.allItems() {
+			expected = massAggregator{}.Merge(expected, massAggregator{}.Lift(item))
+		}
+		if summary.mass != expected.mass || summary.maxArea != expected.maxArea {
+			t.Errorf("node %v summary %+v, expected %+v", node.Bounds(), summary, expected)
+		}
+		if int(summary.mass) != node.Size() {
+			t.Errorf("node %v mass %v differs from size %d", node.Bounds(), summary.mass, node.Size())
+		}
+		return true
+	})
+}
+
+func ExampleWithAggregator() {
+	qtree := NewQuadTree(
+		plane.NewEuclidean2D(16.0, 16.0),
+		WithAggregator[float64, massSummary](massAggregator{}),
+	)
+	defer qtree.Close()
+
+	for _, pos := range []geom.Vec[float64]{{X: 1, Y: 1}, {X: 3, Y: 1}, {X: 13, Y: 13}, {X: 15, Y: 15}, {X: 2, Y: 3}} {
+		qtree.Add(newTestItemFromVec(pos))
+	}
+
+	qtree.VisitNodes(func(node NodeView[float64]) bool {
+		summary, _ := NodeViewSummary[float64, massSummary](node)
+		if summary.mass == 0 {
+			return false
+		}
+		fmt.Printf("%v mass=%v centroid=(%.2f,%.2f)\n", node.Bounds(), summary.mass,
+			summary.weighted.X/summary.mass, summary.weighted.Y/summary.mass)
+		return true
+	})
+
+	// Output:
+	// {(0,0) (16,16)} mass=5 centroid=(6.80,6.60)
+	// {(8,8) (16,16)} mass=2 centroid=(14.00,14.00)
+	// {(0,0) (8,8)} mass=3 centroid=(2.00,1.67)
+}
@@ -1,31 +1,41 @@
 package qtree
 
-import "github.com/kjkrol/gokg/pkg/geom"
+import (
+	"math"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+)
 
 type QuadTreeAppender[T geom.Numeric] struct {
-	maxDepth int
-	capacity int
+	maxDepth   int
+	capacity   int
+	looseness  float64
+	aggregator nodeAggregator[T]
 }
 
 func (qa QuadTreeAppender[T]) add(node *Node[T], item Item[T], depth int) bool {
 
-	if !node.bounds.Contains(item.Bound()) {
+	if !node.loose.Contains(item.Bound()) {
 		return false
 	}
 
 	if node.isNode() && depth < qa.maxDepth {
 		if child := node.findFittingChild(item.Bound()); child != nil {
 			if qa.add(child, item, depth+1) {
+				node.size++
+				refreshSummary(qa.aggregator, node)
 				return true
 			}
 		}
 	}
 	node.items = append(node.items, item)
+	node.size++
 
 	if len(node.items) > qa.capacity && node.isLeaf() && depth < qa.maxDepth {
 		qa.createChilds(node)
 		qa.redistributeItems(node, depth)
 	}
+	refreshSummary(qa.aggregator, node)
 
 	return true
 }
@@ -53,8 +63,25 @@ func (qa QuadTreeAppender[T]) redistributeItems(node *Node[T], depth int) {
 
 func (qa QuadTreeAppender[T]) createChilds(node *Node[T]) {
 	childRectangles := node.bounds.Split()
-	node.childs = make([]*Node[T], qa.capacity)
+	node.childs = make([]*Node[T], len(childRectangles))
 	for i, rect := range childRectangles {
 		node.childs[i] = newNode(rect, node)
+		node.childs[i].loose = qa.loosen(rect, node.loose)
+		refreshSummary(qa.aggregator, node.childs[i])
+	}
+}
+
+// loosen enlarges a child quadrant around its centre by the looseness factor,
+// clipped to the parent's loose bounds.
+func (qa QuadTreeAppender[T]) loosen(rect, parent geom.AABB[T]) geom.AABB[T] {
+	if qa.looseness <= 1 {
+		return rect
+	}
+	grow := func(lo, hi, lower, upper T) (T, T) {
+		pad := (float64(hi) - float64(lo)) * (qa.looseness - 1) / 2
+		return T(math.Max(float64(lo)-pad, float64(lower))), T(math.Min(float64(hi)+pad, float64(upper)))
 	}
+	x1, x2 := grow(rect.TopLeft.X, rect.BottomRight.X, parent.TopLeft.X, parent.BottomRight.X)
+	y1, y2 := grow(rect.TopLeft.Y, rect.BottomRight.Y, parent.TopLeft.Y, parent.BottomRight.Y)
+	return geom.NewAABB(geom.NewVec(x1, y1), geom.NewVec(x2, y2))
 }
@@ -1,6 +1,7 @@
 package qtree
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/kjkrol/gokg/pkg/geom"
@@ -266,6 +267,29 @@ func TestQuadTree_Box_CountDepthAllItemsLeafRectangles(t *testing.T) {
 	}
 }
 
+func TestQuadTree_LeafBoundsInQuadrantOrder(t *testing.T) {
+	qtree := NewQuadTree(plane.NewEuclidean2D(16.0, 16.0))
+	defer qtree.Close()
+
+	// Five points in the NE quadrant split the root and then NE itself.
+	for _, pos := range [][2]float64{{9, 1}, {13, 1}, {9, 5}, {13, 5}, {10, 2}} {
+		qtree.Add(newTestItemFromBox(geom.NewAABBAt(geom.NewVec(pos[0], pos[1]), 1, 1)))
+	}
+
+	expected := []geom.AABB[float64]{
+		geom.NewAABBAt(geom.NewVec(0.0, 0.0), 8, 8),  // NW
+		geom.NewAABBAt(geom.NewVec(8.0, 0.0), 4, 4),  // NE.NW
+		geom.NewAABBAt(geom.NewVec(12.0, 0.0), 4, 4), // NE.NE
+		geom.NewAABBAt(geom.NewVec(8.0, 4.0), 4, 4),  // NE.SW
+		geom.NewAABBAt(geom.NewVec(12.0, 4.0), 4, 4), // NE.SE
+		geom.NewAABBAt(geom.NewVec(0.0, 8.0), 8, 8),  // SW
+		geom.NewAABBAt(geom.NewVec(8.0, 8.0), 8, 8),  // SE
+	}
+	if got := qtree.LeafBounds(); !slices.Equal(got, expected) {
+		t.Errorf("LeafBounds() = %v, expected %v", got, expected)
+	}
+}
+
 func TestSortNeighbors_BottomRightTieBreak(t *testing.T) {
 	plane := plane.NewEuclidean2D(16.0, 16.0)
 	qtree := NewQuadTree(plane)
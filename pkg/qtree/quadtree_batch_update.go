@@ -59,14 +59,14 @@ func (c *BatchUpdateCoordinator[T]) removeBatch(root *Node[T], items []Item[T])
 	set := newBatchRemovalSet(items)
 	removed := 0
 
-	dfs.DFS(root, struct{}{}, func(node *Node[T], _ struct{}) (dfs.DFSControl, struct{}) {
+	c.QuadTreeRemover.traversers.DFS(root, struct{}{}, func(node *Node[T], _ struct{}) (dfs.DFSControl, struct{}) {
 		if len(set.items) == 0 {
-			return dfs.DFSControl{Break: true}, struct{}{}
+			return dfs.Stop, struct{}{}
 		}
 		if count := c.removeFromNode(node, set); count > 0 {
 			removed += count
 		}
-		return dfs.DFSControl{}, struct{}{}
+		return dfs.Continue, struct{}{}
 	})
 
 	return removed
@@ -86,6 +86,8 @@ func (c *BatchUpdateCoordinator[T]) removeFromNode(node *Node[T], set *batchRemo
 
 	if removed > 0 {
 		node.items = keep
+		node.resize(-removed)
+		refreshSummaryPath(c.QuadTreeRemover.aggregator, node)
 		c.track(node)
 	}
 
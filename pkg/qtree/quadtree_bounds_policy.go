@@ -0,0 +1,281 @@
+package qtree
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+)
+
+// OutOfBoundsPolicy decides what a QuadTree does with an item whose bounds are
+// not contained in the plane viewport.
+type OutOfBoundsPolicy int
+
+const (
+	// RejectOutOfBounds refuses the item, so Add returns false. This is the
+	// default.
+	RejectOutOfBounds OutOfBoundsPolicy = iota
+	// ClampOutOfBounds indexes the item by its bounds clamped to the viewport;
+	// queries match it against the clamped box.
+	ClampOutOfBounds
+	// WrapOutOfBounds indexes the item by its bounds wrapped around the edges
+	// of a cyclic plane, one fragment per piece of the plane it overlaps.
+	// Queries report the item once however many fragments they hit. On other
+	// planes the item is rejected.
+	WrapOutOfBounds
+	// OverflowOutOfBounds keeps the item in a bucket outside the tree, which
+	// every query scans.
+	OverflowOutOfBounds
+)
+
+func (p OutOfBoundsPolicy) String() string {
+	switch p {
+	case RejectOutOfBounds:
+		return "Reject"
+	case ClampOutOfBounds:
+		return "Clamp"
+	case WrapOutOfBounds:
+		return "Wrap"
+	case OverflowOutOfBounds:
+		return "Overflow"
+	}
+	return fmt.Sprintf("OutOfBoundsPolicy(%d)", int(p))
+}
+
+// placement stands in for an item indexed by adjusted bounds: its box clamped
+// to the viewport, or one fragment of a box wrapped around a cyclic plane.
+// Fragments of the same item share first, which is the fragment counted when
+// items are tallied.
+type placement[T geom.Numeric] struct {
+	item  Item[T]
+	bound geom.AABB[T]
+	first *placement[T]
+	parts int
+}
+
+func (p *placement[T]) Bound() geom.AABB[T] {
+	return p.bound
+}
+
+func (p *placement[T]) SameID(other Item[T]) bool {
+	return p.item.SameID(original(other))
+}
+
+// duplicate reports whether p is a fragment other than the first one.
+func (p *placement[T]) duplicate() bool {
+	return p.first != p
+}
+
+// original returns the item a stored entry stands for.
+func original[T geom.Numeric](entry Item[T]) Item[T] {
+	if p, ok := entry.(*placement[T]); ok {
+		return p.item
+	}
+	return entry
+}
+
+// duplicateEntry reports whether entry is a fragment other than the first one
+// of a wrapped item.
+func duplicateEntry[T geom.Numeric](entry Item[T]) bool {
+	p, ok := entry.(*placement[T])
+	return ok && p.duplicate()
+}
+
+// hasOutOfBounds reports whether any item was stored through the out-of-bounds
+// policy; only then do query results need resolving.
+func (t *QuadTree[T]) hasOutOfBounds() bool {
+	return len(t.placed) > 0 || len(t.overflow) > 0
+}
+
+func (t *QuadTree[T]) addOutOfBounds(item Item[T]) bool {
+	switch t.boundsPolicy {
+	case ClampOutOfBounds:
+		return t.place(item, []geom.AABB[T]{clampAABB(item.Bound(), t.root.loose)})
+	case WrapOutOfBounds:
+		if !IsCyclic(t.space) {
+			return false
+		}
+		return t.place(item, areaFragments(t.space, item.Bound()))
+	case OverflowOutOfBounds:
+		t.overflow = append(t.overflow, item)
+		return true
+	}
+	return false
+}
+
+// place stores one placement per bound, all standing in for item.
+func (t *QuadTree[T]) place(item Item[T], bounds []geom.AABB[T]) bool {
+	group := make([]*placement[T], 0, len(bounds))
+	for _, bound := range bounds {
+		p := &placement[T]{item: item, bound: bound, parts: len(bounds)}
+		p.first = p
+		if len(group) > 0 {
+			p.first = group[0]
+		}
+		if !t.appender.add(t.root, p, 0) {
+			for _, added := range group {
+				t.remover.remove(t.root, added)
+			}
+			return false
+		}
+		group = append(group, p)
+	}
+	if t.placed == nil {
+		t.placed = make(map[Item[T]][]*placement[T])
+	}
+	t.placed[item] = append(t.placed[item], group...)
+	t.fragments += len(group) - 1
+	return true
+}
+
+func (t *QuadTree[T]) removeOutOfBounds(item Item[T]) bool {
+	if i := slices.Index(t.overflow, item); i >= 0 {
+		t.overflow = slices.Delete(t.overflow, i, i+1)
+		return true
+	}
+	group := t.takePlacements(item)
+	for _, p := range group {
+		t.remover.remove(t.root, p)
+	}
+	return len(group) > 0
+}
+
+// takePlacements unregisters the placements made by the latest Add of item and
+// returns them.
+func (t *QuadTree[T]) takePlacements(item Item[T]) []*placement[T] {
+	placed := t.placed[item]
+	if len(placed) == 0 {
+		return nil
+	}
+	last := placed[len(placed)-1]
+	group := placed[len(placed)-last.parts:]
+	if rest := placed[:len(placed)-last.parts]; len(rest) > 0 {
+		t.placed[item] = rest
+	} else {
+		delete(t.placed, item)
+	}
+	t.fragments -= len(group) - 1
+	return group
+}
+
+// takeRemovals prepares a batch removal: items kept in the overflow bucket are
+// dropped from it, and placed items are replaced by their placements, which
+// the coordinator then removes from the nodes.
+func (t *QuadTree[T]) takeRemovals(items []Item[T]) []Item[T] {
+	entries := make([]Item[T], 0, len(items))
+	for _, item := range items {
+		if i := slices.Index(t.overflow, item); i >= 0 {
+			t.overflow = slices.Delete(t.overflow, i, i+1)
+			continue
+		}
+		if group := t.takePlacements(item); len(group) > 0 {
+			for _, p := range group {
+				entries = append(entries, p)
+			}
+			continue
+		}
+		entries = append(entries, item)
+	}
+	return entries
+}
+
+// resolve turns stored entries into the items they stand for, reporting every
+// item once, and adds the overflow items accepted by match. Without
+// out-of-bounds items the entries are returned unchanged.
+func (t *QuadTree[T]) resolve(entries []Item[T], match func(Item[T]) bool) []Item[T] {
+	if !t.hasOutOfBounds() {
+		return entries
+	}
+	var seen map[*placement[T]]struct{}
+	items := entries[:0]
+	for _, entry := range entries {
+		p, ok := entry.(*placement[T])
+		if !ok {
+			items = append(items, entry)
+			continue
+		}
+		if p.parts > 1 {
+			if _, dup := seen[p.first]; dup {
+				continue
+			}
+			if seen == nil {
+				seen = make(map[*placement[T]]struct{})
+			}
+			seen[p.first] = struct{}{}
+		}
+		items = append(items, p.item)
+	}
+	for _, item := range t.overflow {
+		if match(item) {
+			items = append(items, item)
+		}
+	}
+	sortItems(items)
+	return items
+}
+
+// fragmentTally collects the fragments of wrapped items a counting walk has
+// counted. A wrapped item counts once however many of its fragments match, so
+// the fragments beyond the first of each item are taken off the count again.
+// Placements of exclude, the target of a neighbour count, are left out, as the
+// walk does not count them. A nil tally ignores everything.
+type fragmentTally[T geom.Numeric] struct {
+	exclude Item[T]
+	matched int
+	groups  map[*placement[T]]struct{}
+}
+
+// newFragmentTally returns a tally for a counting walk, or nil when no item is
+// stored in several fragments.
+func (t *QuadTree[T]) newFragmentTally(exclude Item[T]) *fragmentTally[T] {
+	if t.fragments == 0 {
+		return nil
+	}
+	return &fragmentTally[T]{exclude: exclude}
+}
+
+func (f *fragmentTally[T]) note(entry Item[T]) {
+	if f == nil {
+		return
+	}
+	p, ok := entry.(*placement[T])
+	if !ok || p.parts == 1 || p.item == f.exclude {
+		return
+	}
+	f.matched++
+	if f.groups == nil {
+		f.groups = make(map[*placement[T]]struct{})
+	}
+	f.groups[p.first] = struct{}{}
+}
+
+// repeated returns how many of the noted fragments belong to an item already
+// counted through another one.
+func (f *fragmentTally[T]) repeated() int {
+	if f == nil {
+		return 0
+	}
+	return f.matched - len(f.groups)
+}
+
+// countOverflow returns how many items of the overflow bucket match accepts.
+func (t *QuadTree[T]) countOverflow(match func(Item[T]) bool) int {
+	count := 0
+	for _, item := range t.overflow {
+		if match(item) {
+			count++
+		}
+	}
+	return count
+}
+
+// clampAABB moves both corners of box into bounds.
+func clampAABB[T geom.Numeric](box, bounds geom.AABB[T]) geom.AABB[T] {
+	clamp := func(v geom.Vec[T]) geom.Vec[T] {
+		return geom.NewVec(
+			min(max(v.X, bounds.TopLeft.X), bounds.BottomRight.X),
+			min(max(v.Y, bounds.TopLeft.Y), bounds.BottomRight.Y),
+		)
+	}
+	return geom.NewAABB(clamp(box.TopLeft), clamp(box.BottomRight))
+}
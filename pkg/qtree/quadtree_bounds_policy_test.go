@@ -0,0 +1,318 @@
+package qtree
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokg/pkg/plane"
+	"github.com/kjkrol/goku/pkg/sliceutils"
+)
+
+func TestQuadTree_AddOutOfBounds(t *testing.T) {
+	euclidean := plane.NewEuclidean2D(64.0, 64.0)
+	toroidal := plane.NewToroidal2D(64.0, 64.0)
+	tests := []struct {
+		space  plane.Space2D[float64]
+		policy OutOfBoundsPolicy
+		want   bool
+	}{
+		{space: euclidean, policy: RejectOutOfBounds, want: false},
+		{space: euclidean, policy: ClampOutOfBounds, want: true},
+		{space: euclidean, policy: WrapOutOfBounds, want: false},
+		{space: euclidean, policy: OverflowOutOfBounds, want: true},
+		{space: toroidal, policy: RejectOutOfBounds, want: false},
+		{space: toroidal, policy: ClampOutOfBounds, want: true},
+		{space: toroidal, policy: WrapOutOfBounds, want: true},
+		{space: toroidal, policy: OverflowOutOfBounds, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.space.Name()+"/"+tt.policy.String(), func(t *testing.T) {
+			qtree := NewQuadTree(tt.space, WithOutOfBoundsPolicy[float64](tt.policy))
+			defer qtree.Close()
+
+			item := newTestItemFromPos(62.0, 10.0, 4.0, 4.0)
+			if got := qtree.Add(item); got != tt.want {
+				t.Fatalf("Add = %v, expected %v", got, tt.want)
+			}
+			want := 0
+			if tt.want {
+				want = 1
+			}
+			if got := qtree.Count(); got != want {
+				t.Errorf("Count = %d, expected %d", got, want)
+			}
+			if got := len(qtree.AllItems()); got != want {
+				t.Errorf("len(AllItems) = %d, expected %d", got, want)
+			}
+			if got := qtree.Remove(item); got != tt.want {
+				t.Errorf("Remove = %v, expected %v", got, tt.want)
+			}
+			if qtree.Count() != 0 || qtree.root.size != 0 || qtree.hasOutOfBounds() {
+				t.Errorf("expected an empty tree after Remove, got %d items", qtree.Count())
+			}
+		})
+	}
+}
+
+func TestQuadTree_OutOfBoundsQueries(t *testing.T) {
+	tests := []struct {
+		space  plane.Space2D[float64]
+		policy OutOfBoundsPolicy
+	}{
+		{space: plane.NewEuclidean2D(64.0, 64.0), policy: ClampOutOfBounds},
+		{space: plane.NewEuclidean2D(64.0, 64.0), policy: OverflowOutOfBounds},
+		{space: plane.NewToroidal2D(64.0, 64.0), policy: WrapOutOfBounds},
+	}
+	for _, tt := range tests {
+		t.Run(tt.space.Name()+"/"+tt.policy.String(), func(t *testing.T) {
+			qtree := NewQuadTree(tt.space, WithOutOfBoundsPolicy[float64](tt.policy))
+			defer qtree.Close()
+
+			rnd := rand.New(rand.NewSource(49))
+			inside := addRandomBoxes(qtree, rnd, 150)
+			outside := make([]*TestItem[float64], 0, 30)
+			for range 30 {
+				pos := geom.NewVec(rnd.Float64()*80-8, rnd.Float64()*80-8)
+				item := newTestItemFromBox(geom.NewAABBAt(pos, 1+rnd.Float64()*6, 1+rnd.Float64()*6))
+				if !qtree.Add(item) {
+					t.Fatalf("Add(%v) = false", item.AABB)
+				}
+				outside = append(outside, item)
+			}
+			all := append(append([]*TestItem[float64]{}, inside...), outside...)
+			verifySubtreeSizes(t, qtree.root)
+
+			if got := qtree.Count(); got != len(all) {
+				t.Errorf("Count = %d, expected %d", got, len(all))
+			}
+			if got := qtree.AllItems(); !sliceutils.SameElements(got, toItems(all)) {
+				t.Errorf("AllItems returned %d items, expected %d", len(got), len(all))
+			}
+
+			// Each item is matched by the boxes the policy indexes it by.
+			indexed := func(item *TestItem[float64]) []geom.AABB[float64] {
+				switch tt.policy {
+				case ClampOutOfBounds:
+					return []geom.AABB[float64]{clampAABB(item.AABB, tt.space.Viewport())}
+				case WrapOutOfBounds:
+					return areaFragments(tt.space, item.AABB)
+				}
+				return []geom.AABB[float64]{item.AABB}
+			}
+			for range 100 {
+				pos := geom.NewVec(rnd.Float64()*64, rnd.Float64()*64)
+				area := geom.NewAABBAt(pos, rnd.Float64()*24, rnd.Float64()*24)
+				fragments := areaFragments(tt.space, area)
+				expected := make([]Item[float64], 0)
+				for _, item := range all {
+					for _, box := range indexed(item) {
+						if intersectsAny(box, fragments) {
+							expected = append(expected, item)
+							break
+						}
+					}
+				}
+				if got := qtree.FindInAABB(area); !sliceutils.SameElements(got, expected) {
+					t.Fatalf("FindInAABB(%v) returned %d items, expected %d", area, len(got), len(expected))
+				}
+				if got := qtree.CountInAABB(area); got != len(expected) {
+					t.Fatalf("CountInAABB(%v) = %d, expected %d", area, got, len(expected))
+				}
+				got := Aggregate(qtree, area, Monoid[float64, int]{
+					Lift:    func(Item[float64]) int { return 1 },
+					Combine: func(a, b int) int { return a + b },
+				})
+				if got != len(expected) {
+					t.Fatalf("Aggregate(%v) = %d, expected %d", area, got, len(expected))
+				}
+			}
+
+			// Wide margins let whole subtrees count by size around targets that
+			// are themselves stored out of bounds.
+			for i, target := range outside {
+				margin := float64(i % 5 * 6)
+				if got, found := qtree.CountNeighbors(target, margin), qtree.FindNeighbors(target, margin); got != len(found) {
+					t.Fatalf("CountNeighbors(%v, %v) = %d, expected %d", target.AABB, margin, got, len(found))
+				}
+			}
+
+			for _, item := range outside {
+				if !qtree.Remove(item) {
+					t.Fatalf("Remove(%v) = false", item.AABB)
+				}
+			}
+			verifySubtreeSizes(t, qtree.root)
+			if qtree.hasOutOfBounds() || qtree.Count() != len(inside) {
+				t.Errorf("Count = %d after removing the out-of-bounds items, expected %d", qtree.Count(), len(inside))
+			}
+		})
+	}
+}
+
+func TestQuadTree_WrappedItemReportedOnce(t *testing.T) {
+	qtree := NewQuadTree(plane.NewToroidal2D(64.0, 64.0), WithOutOfBoundsPolicy[float64](WrapOutOfBounds))
+	defer qtree.Close()
+
+	corner := newTestItemFromPos(62.0, 62.0, 4.0, 4.0)
+	left := newTestItemFromPos(0.0, 0.0, 1.0, 1.0)
+	right := newTestItemFromPos(62.5, 62.5, 1.0, 1.0)
+	qtree.AddAll([]Item[float64]{corner, left, right})
+
+	if got := qtree.Stats().Items; got != 6 {
+		t.Errorf("Stats().Items = %d, expected one entry per fragment (6)", got)
+	}
+	if got := qtree.FindInAABB(geom.NewAABBAt(geom.NewVec(-4.0, -4.0), 8, 8)); len(got) != 3 {
+		t.Errorf("FindInAABB across the corner returned %v, expected 3 items", got)
+	}
+	if got := qtree.FindNeighbors(left, 0); len(got) != 1 || got[0] != Item[float64](corner) {
+		t.Errorf("FindNeighbors(left) = %v, expected only the wrapped item", got)
+	}
+	if got := qtree.CollidingPairs(); len(got) != 2 {
+		t.Errorf("CollidingPairs returned %d pairs, expected 2", len(got))
+	}
+	if path, ok := qtree.PathOf(corner); !ok {
+		t.Errorf("PathOf(wrapped item) = %v, %v", path, ok)
+	}
+
+	items := 0
+	qtree.VisitNodes(func(node NodeView[float64]) bool {
+		for range node.Items() {
+			items++
+		}
+		return true
+	})
+	if items != 3 {
+		t.Errorf("node views yielded %d items, expected 3", items)
+	}
+}
+
+func TestQuadTree_SeamStraddlingNeighbors(t *testing.T) {
+	space := plane.NewToroidal2D(64.0, 64.0)
+	qtree := NewQuadTree(space, WithOutOfBoundsPolicy[float64](WrapOutOfBounds))
+	defer qtree.Close()
+
+	rnd := rand.New(rand.NewSource(50))
+	items := make([]*TestItem[float64], 0, 200)
+	for range 200 {
+		pos := geom.NewVec(rnd.Float64()*64, rnd.Float64()*64)
+		item := newTestItemFromBox(geom.NewAABBAt(pos, rnd.Float64()*8, rnd.Float64()*8))
+		if !qtree.Add(item) {
+			t.Fatalf("Add(%v) = false", item.AABB)
+		}
+		items = append(items, item)
+	}
+
+	// The brute force measures every pair of wrapped fragments.
+	distance := space.AABBDistance()
+	between := func(a, b geom.AABB[float64]) float64 {
+		best := math.Inf(1)
+		for _, fa := range areaFragments(space, a) {
+			for _, fb := range areaFragments(space, b) {
+				best = min(best, distance(fa, fb))
+			}
+		}
+		return best
+	}
+	neighbors := func(target *TestItem[float64], margin float64) []Item[float64] {
+		found := make([]Item[float64], 0)
+		for _, item := range items {
+			if item != target && between(target.AABB, item.AABB) <= margin {
+				found = append(found, item)
+			}
+		}
+		return found
+	}
+
+	collisions := 0
+	for i, target := range items {
+		collisions += len(neighbors(target, 0))
+		if i >= 100 {
+			continue
+		}
+		margin := float64(i % 4)
+		expected := neighbors(target, margin)
+		if got := qtree.FindNeighbors(target, margin); !sliceutils.SameElements(got, expected) {
+			t.Fatalf("FindNeighbors(%v, %v) = %v, expected %v", target.AABB, margin, got, expected)
+		}
+		if got := qtree.CountNeighbors(target, margin); got != len(expected) {
+			t.Fatalf("CountNeighbors(%v, %v) = %d, expected %d", target.AABB, margin, got, len(expected))
+		}
+	}
+	if got := len(qtree.CollidingPairs()); got != collisions/2 {
+		t.Errorf("CollidingPairs returned %d pairs, expected %d", got, collisions/2)
+	}
+
+	for _, item := range items[:150] {
+		if !qtree.Remove(item) {
+			t.Fatalf("Remove(%v) = false", item.AABB)
+		}
+	}
+	verifySubtreeSizes(t, qtree.root)
+	if got := qtree.AllItems(); !sliceutils.SameElements(got, toItems(items[150:])) {
+		t.Errorf("AllItems returned %d items after the removals, expected %d", len(got), 50)
+	}
+}
+
+func TestQuadTree_BatchUpdateOutOfBounds(t *testing.T) {
+	for _, policy := range []OutOfBoundsPolicy{ClampOutOfBounds, WrapOutOfBounds, OverflowOutOfBounds} {
+		t.Run(policy.String(), func(t *testing.T) {
+			qtree := NewQuadTree(plane.NewToroidal2D(64.0, 64.0), WithOutOfBoundsPolicy[float64](policy))
+			defer qtree.Close()
+
+			inside := newTestItemFromPos(10.0, 10.0, 2.0, 2.0)
+			seam := newTestItemFromPos(63.0, 20.0, 2.0, 2.0)
+			qtree.BatchUpdate(nil, []Item[float64]{inside, seam}, false)
+			if got := qtree.Count(); got != 2 {
+				t.Fatalf("Count = %d after the batch insert, expected 2", got)
+			}
+
+			moved := newTestItemFromPos(30.0, 63.0, 2.0, 2.0)
+			qtree.BatchUpdate([]Item[float64]{seam}, []Item[float64]{moved}, true)
+			if got := qtree.AllItems(); !sliceutils.SameElements(got, []Item[float64]{inside, moved}) {
+				t.Errorf("AllItems = %v after the batch update", got)
+			}
+			verifySubtreeSizes(t, qtree.root)
+		})
+	}
+}
+
+func TestOutOfBoundsPolicy_String(t *testing.T) {
+	for policy, want := range map[OutOfBoundsPolicy]string{
+		RejectOutOfBounds:    "Reject",
+		ClampOutOfBounds:     "Clamp",
+		WrapOutOfBounds:      "Wrap",
+		OverflowOutOfBounds:  "Overflow",
+		OutOfBoundsPolicy(9): "OutOfBoundsPolicy(9)",
+	} {
+		if got := policy.String(); got != want {
+			t.Errorf("String() = %q, expected %q", got, want)
+		}
+	}
+}
+
+func ExampleWithOutOfBoundsPolicy() {
+	space := plane.NewEuclidean2D(64, 64)
+	strict := NewQuadTree(space)
+	defer strict.Close()
+	clamped := NewQuadTree(space, WithOutOfBoundsPolicy[int](ClampOutOfBounds))
+	defer clamped.Close()
+
+	// The item reaches past the right edge of the viewport.
+	item := newTestItemFromPos(60, 10, 8, 4)
+	fmt.Println(strict.Add(item), clamped.Add(item))
+	fmt.Println(clamped.FindInAABB(geom.NewAABBAt(geom.NewVec(62, 10), 1, 1)))
+	// Output:
+	// false true
+	// [{(60,10) (68,14)}]
+}
+
+func toItems(items []*TestItem[float64]) []Item[float64] {
+	converted := make([]Item[float64], len(items))
+	for i, item := range items {
+		converted[i] = item
+	}
+	return converted
+}
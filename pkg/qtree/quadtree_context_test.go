@@ -0,0 +1,97 @@
+package qtree
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokg/pkg/plane"
+	"github.com/kjkrol/goku/pkg/sliceutils"
+)
+
+func TestQuadTree_FindInAABB_MatchesBruteForce(t *testing.T) {
+	for _, space := range []plane.Space2D[float64]{
+		plane.NewEuclidean2D(128.0, 128.0),
+		plane.NewToroidal2D(128.0, 128.0),
+	} {
+		t.Run(space.Name(), func(t *testing.T) {
+			qtree := NewQuadTree(space)
+			defer qtree.Close()
+
+			rnd := rand.New(rand.NewSource(41))
+			items := addRandomBoxes(qtree, rnd, 300)
+
+			for range 50 {
+				area := geom.NewAABBAt(
+					geom.NewVec(rnd.Float64()*128, rnd.Float64()*128),
+					rnd.Float64()*64, rnd.Float64()*64,
+				)
+				probe := space.WrapAABB(area)
+				expected := []Item[float64]{}
+				for _, item := range items {
+					if space.WrapAABB(item.Bound()).IntersectsWithFrags(probe) {
+						expected = append(expected, item)
+					}
+				}
+				got := qtree.FindInAABB(area)
+				if !sliceutils.SameElements(got, expected) {
+					t.Errorf("FindInAABB(%v) = %v, expected %v", area, got, expected)
+				}
+				if len(got) != qtree.CountInAABB(area) {
+					t.Errorf("FindInAABB(%v) found %d items, CountInAABB reports %d", area, len(got), qtree.CountInAABB(area))
+				}
+			}
+		})
+	}
+}
+
+func TestQuadTree_CtxQueries_MatchPlainQueries(t *testing.T) {
+	qtree := NewQuadTree(plane.NewToroidal2D(128.0, 128.0))
+	defer qtree.Close()
+
+	rnd := rand.New(rand.NewSource(42))
+	items := addRandomBoxes(qtree, rnd, 300)
+	ctx := context.Background()
+
+	all, err := qtree.AllItemsCtx(ctx)
+	if err != nil || !sliceutils.SameElements(all, qtree.AllItems()) {
+		t.Errorf("AllItemsCtx = %v, %v; expected %v", all, err, qtree.AllItems())
+	}
+
+	for i, target := range items[:30] {
+		margin := float64(i % 10)
+		neighbors, err := qtree.FindNeighborsCtx(ctx, target, margin)
+		if err != nil || !sliceutils.SameElements(neighbors, qtree.FindNeighbors(target, margin)) {
+			t.Errorf("FindNeighborsCtx(%v, %v) = %v, %v; expected %v", target, margin, neighbors, err, qtree.FindNeighbors(target, margin))
+		}
+
+		area := geom.NewAABBAround(target.Bound().TopLeft, margin)
+		found, err := qtree.FindInAABBCtx(ctx, area)
+		if err != nil || !sliceutils.SameElements(found, qtree.FindInAABB(area)) {
+			t.Errorf("FindInAABBCtx(%v) = %v, %v; expected %v", area, found, err, qtree.FindInAABB(area))
+		}
+	}
+}
+
+func TestQuadTree_CtxQueries_ReturnContextError(t *testing.T) {
+	qtree := NewQuadTree(plane.NewEuclidean2D(128.0, 128.0))
+	defer qtree.Close()
+
+	rnd := rand.New(rand.NewSource(43))
+	items := addRandomBoxes(qtree, rnd, 300)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if got, err := qtree.AllItemsCtx(ctx); got != nil || !errors.Is(err, context.Canceled) {
+		t.Errorf("AllItemsCtx = %v, %v; expected nil, context.Canceled", got, err)
+	}
+	if got, err := qtree.FindNeighborsCtx(ctx, items[0], 10); got != nil || !errors.Is(err, context.Canceled) {
+		t.Errorf("FindNeighborsCtx = %v, %v; expected nil, context.Canceled", got, err)
+	}
+	if got, err := qtree.FindInAABBCtx(ctx, qtree.root.bounds); got != nil || !errors.Is(err, context.Canceled) {
+		t.Errorf("FindInAABBCtx = %v, %v; expected nil, context.Canceled", got, err)
+	}
+}
@@ -0,0 +1,183 @@
+package qtree
+
+import (
+	"math"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokg/pkg/plane"
+	"github.com/kjkrol/gokq/pkg/dfs"
+)
+
+// QuadTreeCounter answers counting queries without materializing the matching
+// items. Nodes fully covered by the query are accounted for through their
+// subtree sizes, so the traversal never descends below them.
+type QuadTreeCounter[T geom.Numeric] struct {
+	space      plane.Space2D[T]
+	strategy   QuadTreeFinderStrategy[T]
+	traversers *dfs.TraverserPool[*Node[T], struct{}]
+}
+
+func NewQuadTreeCounter[T geom.Numeric](
+	space plane.Space2D[T],
+	strategy QuadTreeFinderStrategy[T],
+) QuadTreeCounter[T] {
+	return QuadTreeCounter[T]{space: space, strategy: strategy}
+}
+
+func (qc QuadTreeCounter[T]) CountInAABB(root *Node[T], area geom.AABB[T]) int {
+	return qc.countInAABB(root, area, nil)
+}
+
+// countInAABB is CountInAABB that also notes the wrapped fragments it counts in
+// tally, when one is given.
+func (qc QuadTreeCounter[T]) countInAABB(root *Node[T], area geom.AABB[T], tally *fragmentTally[T]) int {
+	var buf [MAX_AREA_FRAGMENTS]geom.AABB[T]
+	fragments := appendAreaFragments(buf[:0], qc.space, area)
+	total := 0
+
+	qc.traversers.DFS(root, struct{}{}, func(node *Node[T], _ struct{}) (dfs.DFSControl, struct{}) {
+		if !intersectsAny(node.loose, fragments) {
+			return dfs.SkipChildren, struct{}{}
+		}
+		if coveredByAny(node.loose, fragments) {
+			total += node.size
+			qc.noteSubtree(node, tally)
+			return dfs.SkipChildren, struct{}{}
+		}
+		for _, item := range node.items {
+			if intersectsAny(item.Bound(), fragments) {
+				total++
+				tally.note(item)
+			}
+		}
+		return dfs.Continue, struct{}{}
+	})
+
+	return total
+}
+
+func (qc QuadTreeCounter[T]) CountNeighbors(root *Node[T], target Item[T], margin T) int {
+	return qc.countNeighbors(root, target, margin, nil, nil)
+}
+
+// countNeighbors is CountNeighbors for a target that is also stored through
+// placements, which are left out of the count like the target itself. The
+// wrapped fragments it counts are noted in tally, when one is given.
+func (qc QuadTreeCounter[T]) countNeighbors(
+	root *Node[T],
+	target Item[T],
+	margin T,
+	placements []*placement[T],
+	tally *fragmentTally[T],
+) int {
+	nodeIntersectionDetection := qc.strategy.NodeIntersectionDetectionFactory(target, margin)
+	itemsInRangeDetection := qc.strategy.ItemsInRangeDetectionFactory(target, margin)
+	var buf [MAX_AREA_FRAGMENTS]geom.AABB[T]
+	reach := appendMeasuredBoxes(buf[:0], qc.space, target.Bound())
+	total := 0
+
+	qc.traversers.DFS(root, struct{}{}, func(node *Node[T], _ struct{}) (dfs.DFSControl, struct{}) {
+		if !nodeIntersectionDetection(*node) {
+			return dfs.SkipChildren, struct{}{}
+		}
+		if withinReachOfAny(node.loose, reach, margin) {
+			total += node.size - node.countSame(target) - node.countPlaced(placements)
+			qc.noteSubtree(node, tally)
+			return dfs.SkipChildren, struct{}{}
+		}
+		itemsInRangeDetection(*node, func(item Item[T]) {
+			total++
+			tally.note(item)
+		})
+		return dfs.Continue, struct{}{}
+	})
+
+	return total
+}
+
+// noteSubtree notes in tally the entries of a subtree counted through its size.
+func (qc QuadTreeCounter[T]) noteSubtree(node *Node[T], tally *fragmentTally[T]) {
+	if tally == nil {
+		return
+	}
+	qc.traversers.DFS(node, struct{}{}, func(node *Node[T], _ struct{}) (dfs.DFSControl, struct{}) {
+		for _, item := range node.items {
+			tally.note(item)
+		}
+		return dfs.Continue, struct{}{}
+	})
+}
+
+// MAX_AREA_FRAGMENTS is the largest number of boxes areaFragments returns: the
+// normalized area and the three fragments of an area wrapping across a corner.
+const MAX_AREA_FRAGMENTS int = 4
+
+// areaFragments returns the query area normalized by the plane; on cyclic
+// planes an area crossing the edge is split into its wrapped fragments.
+func areaFragments[T geom.Numeric](space plane.Space2D[T], area geom.AABB[T]) []geom.AABB[T] {
+	return appendAreaFragments(make([]geom.AABB[T], 0, MAX_AREA_FRAGMENTS), space, area)
+}
+
+// appendAreaFragments appends the boxes of areaFragments to fragments, so that
+// callers can keep them in a stack buffer.
+func appendAreaFragments[T geom.Numeric](fragments []geom.AABB[T], space plane.Space2D[T], area geom.AABB[T]) []geom.AABB[T] {
+	probe := space.WrapAABB(area)
+	fragments = append(fragments, probe.AABB)
+	probe.VisitFragments(func(_ plane.FragPosition, aabb geom.AABB[T]) bool {
+		fragments = append(fragments, aabb)
+		return true
+	})
+	return fragments
+}
+
+func intersectsAny[T geom.Numeric](box geom.AABB[T], fragments []geom.AABB[T]) bool {
+	for _, fragment := range fragments {
+		if fragment.Intersects(box) {
+			return true
+		}
+	}
+	return false
+}
+
+func coveredByAny[T geom.Numeric](box geom.AABB[T], fragments []geom.AABB[T]) bool {
+	for _, fragment := range fragments {
+		if fragment.Contains(box) {
+			return true
+		}
+	}
+	return false
+}
+
+// appendMeasuredBoxes appends the boxes wrappedDistance measures bound by: bound
+// itself or, on a cyclic plane, its wrapped fragments when it crosses the edge
+// of the viewport.
+func appendMeasuredBoxes[T geom.Numeric](boxes []geom.AABB[T], space plane.Space2D[T], bound geom.AABB[T]) []geom.AABB[T] {
+	if !IsCyclic(space) || space.Viewport().Contains(bound) {
+		return append(boxes, bound)
+	}
+	return appendAreaFragments(boxes, space, bound)
+}
+
+func withinReachOfAny[T geom.Numeric](bounds geom.AABB[T], targets []geom.AABB[T], margin T) bool {
+	for _, target := range targets {
+		if withinReach(bounds, target, margin) {
+			return true
+		}
+	}
+	return false
+}
+
+// withinReach reports whether every box that fits into bounds lies within
+// margin of target. The flat Euclidean distance to the farthest corner is used,
+// which never underestimates the distance measured by any supported plane.
+func withinReach[T geom.Numeric](bounds, target geom.AABB[T], margin T) bool {
+	dx := math.Max(
+		float64(bounds.BottomRight.X)-float64(target.BottomRight.X),
+		float64(target.TopLeft.X)-float64(bounds.TopLeft.X),
+	)
+	dy := math.Max(
+		float64(bounds.BottomRight.Y)-float64(target.BottomRight.Y),
+		float64(target.TopLeft.Y)-float64(bounds.TopLeft.Y),
+	)
+	return math.Hypot(math.Max(dx, 0), math.Max(dy, 0)) <= float64(margin)
+}
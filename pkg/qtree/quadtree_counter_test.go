@@ -0,0 +1,150 @@
+package qtree
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokg/pkg/plane"
+)
+
+func TestQuadTree_CountInAABB_MatchesBruteForce(t *testing.T) {
+	for _, space := range []plane.Space2D[float64]{
+		plane.NewEuclidean2D(128.0, 128.0),
+		plane.NewToroidal2D(128.0, 128.0),
+	} {
+		t.Run(space.Name(), func(t *testing.T) {
+			qtree := NewQuadTree(space)
+			defer qtree.Close()
+
+			rnd := rand.New(rand.NewSource(26))
+			items := addRandomBoxes(qtree, rnd, 300)
+
+			for range 50 {
+				area := geom.NewAABBAt(
+					geom.NewVec(rnd.Float64()*128, rnd.Float64()*128),
+					rnd.Float64()*64, rnd.Float64()*64,
+				)
+				probe := space.WrapAABB(area)
+				expected := 0
+				for _, item := range items {
+					if space.WrapAABB(item.Bound()).IntersectsWithFrags(probe) {
+						expected++
+					}
+				}
+				if got := qtree.CountInAABB(area); got != expected {
+					t.Errorf("CountInAABB(%v) = %d, expected %d", area, got, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestQuadTree_CountNeighbors_MatchesFindNeighbors(t *testing.T) {
+	for _, space := range []plane.Space2D[float64]{
+		plane.NewEuclidean2D(128.0, 128.0),
+		plane.NewToroidal2D(128.0, 128.0),
+	} {
+		t.Run(space.Name(), func(t *testing.T) {
+			qtree := NewQuadTree(space)
+			defer qtree.Close()
+
+			rnd := rand.New(rand.NewSource(27))
+			items := addRandomBoxes(qtree, rnd, 300)
+
+			for i, target := range items[:60] {
+				margin := float64(i % 30)
+				expected := len(qtree.FindNeighbors(target, margin))
+				if got := qtree.CountNeighbors(target, margin); got != expected {
+					t.Errorf("CountNeighbors(%v, %v) = %d, expected %d", target, margin, got, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestQuadTree_SubtreeSizesFollowMutations(t *testing.T) {
+	qtree := NewQuadTree(plane.NewEuclidean2D(64.0, 64.0))
+	defer qtree.Close()
+
+	rnd := rand.New(rand.NewSource(28))
+	items := addRandomBoxes(qtree, rnd, 200)
+	verifySubtreeSizes(t, qtree.root)
+
+	for _, item := range items[:80] {
+		qtree.Remove(item)
+	}
+	verifySubtreeSizes(t, qtree.root)
+
+	toRemove := make([]Item[float64], 0, 60)
+	for _, item := range items[80:140] {
+		toRemove = append(toRemove, item)
+	}
+	toAdd := []Item[float64]{newTestItemPointAtPos(1.0, 1.0), newTestItemPointAtPos(60.0, 2.0)}
+	qtree.BatchUpdate(toRemove, toAdd, true)
+	verifySubtreeSizes(t, qtree.root)
+
+	if qtree.root.size != qtree.Count() {
+		t.Errorf("root size %d differs from count %d", qtree.root.size, qtree.Count())
+	}
+}
+
+func TestAggregate_SumsAreasInsideQuery(t *testing.T) {
+	space := plane.NewToroidal2D(64.0, 64.0)
+	qtree := NewQuadTree(space)
+	defer qtree.Close()
+
+	rnd := rand.New(rand.NewSource(29))
+	items := addRandomBoxes(qtree, rnd, 150)
+
+	area := geom.NewAABBAt(geom.NewVec(40.0, 50.0), 40, 30) // wraps on both axes
+	probe := space.WrapAABB(area)
+	expected := 0.0
+	for _, item := range items {
+		if space.WrapAABB(item.Bound()).IntersectsWithFrags(probe) {
+			expected += boxArea(item.Bound())
+		}
+	}
+
+	got := Aggregate(qtree, area, Monoid[float64, float64]{
+		Lift:    func(item Item[float64]) float64 { return boxArea(item.Bound()) },
+		Combine: func(a, b float64) float64 { return a + b },
+	})
+	if math.Abs(got-expected) > 1e-9 {
+		t.Errorf("Aggregate = %v, expected %v", got, expected)
+	}
+}
+
+func addRandomBoxes(qtree *QuadTree[float64], rnd *rand.Rand, n int) []*TestItem[float64] {
+	viewport := qtree.root.bounds
+	items := make([]*TestItem[float64], 0, n)
+	for len(items) < n {
+		size := rnd.Float64() * 4
+		pos := geom.NewVec(
+			rnd.Float64()*(viewport.BottomRight.X-size),
+			rnd.Float64()*(viewport.BottomRight.Y-size),
+		)
+		item := newTestItemFromBox(geom.NewAABBAt(pos, size, size))
+		if qtree.Add(item) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func verifySubtreeSizes(t *testing.T, node *Node[float64]) int {
+	t.Helper()
+	size := len(node.items)
+	for _, child := range node.childs {
+		size += verifySubtreeSizes(t, child)
+	}
+	if node.size != size {
+		t.Errorf("node %v reports size %d, expected %d", node.bounds, node.size, size)
+	}
+	return size
+}
+
+func boxArea(box geom.AABB[float64]) float64 {
+	return (box.BottomRight.X - box.TopLeft.X) * (box.BottomRight.Y - box.TopLeft.Y)
+}
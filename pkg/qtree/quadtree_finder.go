@@ -1,12 +1,15 @@
 package qtree
 
 import (
+	"context"
+
 	"github.com/kjkrol/gokg/pkg/geom"
 	"github.com/kjkrol/gokq/pkg/dfs"
 )
 
 type QuadTreeFinder[T geom.Numeric] struct {
-	strategy QuadTreeFinderStrategy[T]
+	strategy   QuadTreeFinderStrategy[T]
+	traversers *dfs.TraverserPool[*Node[T], struct{}]
 }
 
 func NewQuadTreeFinder[T geom.Numeric](strategy QuadTreeFinderStrategy[T]) QuadTreeFinder[T] {
@@ -14,19 +17,68 @@ func NewQuadTreeFinder[T geom.Numeric](strategy QuadTreeFinderStrategy[T]) QuadT
 }
 
 func (qf QuadTreeFinder[T]) FindNeighbors(root *Node[T], target Item[T], margin T) []Item[T] {
+	neighbors := make([]Item[T], 0)
+	qf.traversers.DFS(root, struct{}{}, qf.neighborsStep(target, margin, &neighbors))
+	sortItems(neighbors)
+	return neighbors
+}
 
+// FindNeighborsCtx is FindNeighbors that stops with ctx.Err() once ctx is done.
+func (qf QuadTreeFinder[T]) FindNeighborsCtx(ctx context.Context, root *Node[T], target Item[T], margin T) ([]Item[T], error) {
+	neighbors := make([]Item[T], 0)
+	if err := dfs.DFSContext(ctx, root, struct{}{}, qf.neighborsStep(target, margin, &neighbors)); err != nil {
+		return nil, err
+	}
+	sortItems(neighbors)
+	return neighbors, nil
+}
+
+// FindInAABB returns the items intersecting any of the fragments of a query
+// area, as produced by areaFragments.
+func (qf QuadTreeFinder[T]) FindInAABB(root *Node[T], fragments []geom.AABB[T]) []Item[T] {
+	found := make([]Item[T], 0)
+	qf.traversers.DFS(root, struct{}{}, inAABBStep(fragments, &found))
+	sortItems(found)
+	return found
+}
+
+// FindInAABBCtx is FindInAABB that stops with ctx.Err() once ctx is done.
+func (qf QuadTreeFinder[T]) FindInAABBCtx(ctx context.Context, root *Node[T], fragments []geom.AABB[T]) ([]Item[T], error) {
+	found := make([]Item[T], 0)
+	if err := dfs.DFSContext(ctx, root, struct{}{}, inAABBStep(fragments, &found)); err != nil {
+		return nil, err
+	}
+	sortItems(found)
+	return found, nil
+}
+
+func (qf QuadTreeFinder[T]) neighborsStep(
+	target Item[T],
+	margin T,
+	neighbors *[]Item[T],
+) dfs.DFSStepFunc[*Node[T], struct{}] {
 	nodeIntersectionDetection := qf.strategy.NodeIntersectionDetectionFactory(target, margin)
 	itemsInRangeDetection := qf.strategy.ItemsInRangeDetectionFactory(target, margin)
-	neighbors := make([]Item[T], 0)
 
-	dfs.DFS(root, struct{}{}, func(node *Node[T], _ struct{}) (dfs.DFSControl, struct{}) {
+	return func(node *Node[T], _ struct{}) (dfs.DFSControl, struct{}) {
 		if !nodeIntersectionDetection(*node) {
-			return dfs.DFSControl{Skip: true}, struct{}{}
+			return dfs.SkipChildren, struct{}{}
 		}
-		itemsInRangeDetection(*node, func(item Item[T]) { neighbors = append(neighbors, item) })
-		return dfs.DFSControl{}, struct{}{}
-	})
+		itemsInRangeDetection(*node, func(item Item[T]) { *neighbors = append(*neighbors, item) })
+		return dfs.Continue, struct{}{}
+	}
+}
 
-	sortItems(neighbors)
-	return neighbors
+func inAABBStep[T geom.Numeric](fragments []geom.AABB[T], found *[]Item[T]) dfs.DFSStepFunc[*Node[T], struct{}] {
+	return func(node *Node[T], _ struct{}) (dfs.DFSControl, struct{}) {
+		if !intersectsAny(node.loose, fragments) {
+			return dfs.SkipChildren, struct{}{}
+		}
+		for _, item := range node.items {
+			if intersectsAny(item.Bound(), fragments) {
+				*found = append(*found, item)
+			}
+		}
+		return dfs.Continue, struct{}{}
+	}
 }
@@ -32,9 +32,9 @@ func (s DefaultQuadTreeFinderStrategy[T]) NodeIntersectionDetectionFactory(
 	probe := s.Space2D.WrapAABB(target.Bound())
 	s.Space2D.Expand(&probe, margin)
 	return func(node Node[T]) bool {
-		intersection := node.bounds.Intersects(probe.AABB)
+		intersection := node.loose.Intersects(probe.AABB)
 		probe.VisitFragments(func(pos plane.FragPosition, aabb geom.AABB[T]) bool {
-			intersection = intersection || node.bounds.Intersects(aabb)
+			intersection = intersection || node.loose.Intersects(aabb)
 			return true
 		})
 		return intersection
@@ -45,7 +45,7 @@ func (s DefaultQuadTreeFinderStrategy[T]) ItemsInRangeDetectionFactory(
 	target Item[T],
 	margin T,
 ) ItemsInRangeDetection[T] {
-	boundingBoxDistance := s.Space2D.AABBDistance()
+	boundingBoxDistance := wrappedDistance(s.Space2D)
 	return func(node Node[T], inRangeApply func(Item[T])) {
 		for _, item := range node.items {
 			if item.SameID(target) {
@@ -57,3 +57,27 @@ func (s DefaultQuadTreeFinderStrategy[T]) ItemsInRangeDetectionFactory(
 		}
 	}
 }
+
+// wrappedDistance returns the distance between two boxes on the plane. The
+// metric of a cyclic plane expects boxes inside the viewport, so there boxes
+// crossing an edge are measured fragment by fragment.
+func wrappedDistance[T geom.Numeric](space plane.Space2D[T]) func(a, b geom.AABB[T]) T {
+	distance := space.AABBDistance()
+	if !IsCyclic(space) {
+		return distance
+	}
+	viewport := space.Viewport()
+	return func(a, b geom.AABB[T]) T {
+		if viewport.Contains(a) && viewport.Contains(b) {
+			return distance(a, b)
+		}
+		fragmentsA, fragmentsB := areaFragments(space, a), areaFragments(space, b)
+		best := distance(fragmentsA[0], fragmentsB[0])
+		for _, fa := range fragmentsA {
+			for _, fb := range fragmentsB {
+				best = min(best, distance(fa, fb))
+			}
+		}
+		return best
+	}
+}
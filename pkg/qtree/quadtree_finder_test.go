@@ -1,6 +1,8 @@
 package qtree
 
 import (
+	"math/rand"
+	"slices"
 	"testing"
 
 	"github.com/kjkrol/gokg/pkg/geom"
@@ -72,3 +74,57 @@ func TestQuadTree_FindNeighbors_ForCyclicBoundedPlane_WithFrags(t *testing.T) {
 		t.Errorf("result %v not equal to expected %v", neighbors, expected)
 	}
 }
+
+func TestQuadTree_Nearest_MatchesBruteForce(t *testing.T) {
+	euclidean := plane.NewEuclidean2D(128.0, 128.0)
+	toroidal := plane.NewToroidal2D(128.0, 128.0)
+	tests := []struct {
+		name  string
+		space plane.Space2D[float64]
+		opts  []QuadTreeOption[float64]
+		spill float64
+	}{
+		{name: "Euclidean", space: euclidean},
+		{name: "Toroidal", space: toroidal},
+		{name: "Loose", space: euclidean, opts: []QuadTreeOption[float64]{WithLooseness[float64](2)}},
+		{name: "Wrap", space: toroidal, opts: []QuadTreeOption[float64]{WithOutOfBoundsPolicy[float64](WrapOutOfBounds)}, spill: 8},
+		{name: "Overflow", space: euclidean, opts: []QuadTreeOption[float64]{WithOutOfBoundsPolicy[float64](OverflowOutOfBounds)}, spill: 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qtree := NewQuadTree(tt.space, tt.opts...)
+			defer qtree.Close()
+
+			rnd := rand.New(rand.NewSource(44))
+			items := make([]Item[float64], 0, 300)
+			for len(items) < 300 {
+				w, h := rnd.Float64()*8, rnd.Float64()*8
+				pos := geom.NewVec(rnd.Float64()*(128-w+tt.spill), rnd.Float64()*(128-h+tt.spill))
+				item := newTestItemFromBox(geom.NewAABBAt(pos, w, h))
+				if qtree.Add(item) {
+					items = append(items, item)
+				}
+			}
+
+			for i := range 40 {
+				pos := geom.NewVec(rnd.Float64()*128, rnd.Float64()*128)
+				k := 1 + i%10
+				expected := make([]float64, 0, len(items))
+				for _, item := range items {
+					expected = append(expected, PointDistance(tt.space, pos, item.Bound()))
+				}
+				slices.Sort(expected)
+
+				got := qtree.Nearest(pos, k)
+				if len(got) != k {
+					t.Fatalf("Nearest(%v, %d) returned %d items", pos, k, len(got))
+				}
+				for j, item := range got {
+					if d := PointDistance(tt.space, pos, item.Bound()); d != expected[j] {
+						t.Errorf("Nearest(%v, %d)[%d] at distance %v, expected %v", pos, k, j, d, expected[j])
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package qtree
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokg/pkg/plane"
+	"github.com/kjkrol/gokq/pkg/hilbert"
+	"github.com/kjkrol/goku/pkg/sliceutils"
+)
+
+func TestQuadTree_HilbertOrder(t *testing.T) {
+	space := plane.NewEuclidean2D(128.0, 128.0)
+	rnd := rand.New(rand.NewSource(32))
+	items := make([]Item[float64], 0, 300)
+	for range 300 {
+		pos := geom.NewVec(rnd.Float64()*120, rnd.Float64()*120)
+		items = append(items, newTestItemFromBox(geom.NewAABBAt(pos, 4, 4)))
+	}
+
+	qtree := NewQuadTree(space, WithHilbertOrder[float64]())
+	defer qtree.Close()
+	if added := qtree.AddAll(items); added != len(items) {
+		t.Fatalf("AddAll placed %d of %d items", added, len(items))
+	}
+
+	got := qtree.AllItems()
+	if !sliceutils.SameElements(got, items) {
+		t.Fatalf("AllItems returned a different set of items")
+	}
+	previous := uint64(0)
+	for _, item := range got {
+		index := hilbert.Index(space, hilbert.Center(item.Bound()), hilbert.DEFAULT_ORDER)
+		if index < previous {
+			t.Fatalf("AllItems out of Hilbert order at %v", item.Bound())
+		}
+		previous = index
+	}
+
+	plain := NewQuadTree(space)
+	defer plain.Close()
+	plain.AddAll(items)
+	if !sliceutils.SameElements(plain.AllItems(), got) {
+		t.Errorf("expected the ordering not to change the stored items")
+	}
+}
+
+func TestQuadTree_AddAllSkipsItemsOutsideViewport(t *testing.T) {
+	qtree := NewQuadTree(plane.NewEuclidean2D(16, 16), WithHilbertOrder[int]())
+	defer qtree.Close()
+
+	first := newTestItemPointAtPos(15, 1)
+	items := []Item[int]{first, newTestItemFromPos(12, 12, 8, 8), newTestItemPointAtPos(1, 1)}
+	if added := qtree.AddAll(items); added != 2 {
+		t.Errorf("expected 2 items to be placed, got %d", added)
+	}
+	if items[0] != Item[int](first) {
+		t.Errorf("expected AddAll to leave the input slice untouched")
+	}
+}
+
+func ExampleWithHilbertOrder() {
+	qtree := NewQuadTree(plane.NewEuclidean2D(4, 4), WithHilbertOrder[int]())
+	defer qtree.Close()
+
+	for y := range 2 {
+		for x := range 2 {
+			qtree.Add(newTestItemPointAtPos(x*2+1, y*2+1))
+		}
+	}
+	for _, item := range qtree.AllItems() {
+		fmt.Println(item.Bound().TopLeft)
+	}
+
+	// Output:
+	// (1,1)
+	// (1,3)
+	// (3,3)
+	// (3,1)
+}
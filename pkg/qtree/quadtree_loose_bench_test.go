@@ -0,0 +1,110 @@
+package qtree
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokg/pkg/plane"
+)
+
+var looseBenchSink int
+
+var looseBenchModes = []struct {
+	name string
+	opts []QuadTreeOption[float64]
+}{
+	{name: "strict"},
+	{name: "loose", opts: []QuadTreeOption[float64]{WithLooseness[float64](2)}},
+}
+
+// newSpriteItems generates mid-sized boxes, a quarter of which straddle the
+// quadrant edges near the root.
+func newSpriteItems(rnd *rand.Rand, n int, size float64) []*TestItem[float64] {
+	items := make([]*TestItem[float64], n)
+	for i := range items {
+		side := 2 + rnd.Float64()*6
+		pos := geom.NewVec(rnd.Float64()*(size-side), rnd.Float64()*(size-side))
+		if i%4 == 0 {
+			pos.X = size/2 - side/2
+		}
+		items[i] = newTestItemFromBox(geom.NewAABBAt(pos, side, side))
+	}
+	return items
+}
+
+func Benchmark_QuadTree_FindNeighbors_Sprites(b *testing.B) {
+	for _, mode := range looseBenchModes {
+		b.Run(mode.name, func(b *testing.B) {
+			qtree := NewQuadTree(plane.NewEuclidean2D(1024.0, 1024.0), mode.opts...)
+			defer qtree.Close()
+			items := newSpriteItems(rand.New(rand.NewSource(1)), 5000, 1024)
+			for _, item := range items {
+				qtree.Add(item)
+			}
+
+			b.ReportAllocs()
+			i := 0
+			for b.Loop() {
+				looseBenchSink += len(qtree.FindNeighbors(items[i%len(items)], 4))
+				i++
+			}
+		})
+	}
+}
+
+func Benchmark_QuadTree_Add_Sprites(b *testing.B) {
+	for _, mode := range looseBenchModes {
+		b.Run(mode.name, func(b *testing.B) {
+			items := newSpriteItems(rand.New(rand.NewSource(2)), 5000, 1024)
+
+			b.ReportAllocs()
+			for b.Loop() {
+				qtree := NewQuadTree(plane.NewEuclidean2D(1024.0, 1024.0), mode.opts...)
+				for _, item := range items {
+					qtree.Add(item)
+				}
+				qtree.Close()
+			}
+		})
+	}
+}
+
+func Benchmark_QuadTree_BatchUpdate_MovingSprites(b *testing.B) {
+	for _, mode := range looseBenchModes {
+		b.Run(mode.name, func(b *testing.B) {
+			qtree := NewQuadTree(plane.NewEuclidean2D(1024.0, 1024.0), mode.opts...)
+			defer qtree.Close()
+			rnd := rand.New(rand.NewSource(3))
+			items := newSpriteItems(rnd, 5000, 1024)
+			for _, item := range items {
+				qtree.Add(item)
+			}
+
+			moved := make([]Item[float64], 0, 200)
+			replacements := make([]Item[float64], 0, 200)
+
+			b.ReportAllocs()
+			for b.Loop() {
+				moved, replacements = moved[:0], replacements[:0]
+				for j := range 200 {
+					idx := rnd.Intn(len(items))
+					old := items[idx]
+					shifted := old.AABB
+					dx := float64(j%3) - 1
+					if shifted.TopLeft.X+dx >= 0 && shifted.BottomRight.X+dx <= 1024 {
+						shifted = geom.NewAABB(
+							geom.NewVec(shifted.TopLeft.X+dx, shifted.TopLeft.Y),
+							geom.NewVec(shifted.BottomRight.X+dx, shifted.BottomRight.Y),
+						)
+					}
+					next := &TestItem[float64]{AABB: shifted, id: old.id}
+					items[idx] = next
+					moved = append(moved, old)
+					replacements = append(replacements, next)
+				}
+				qtree.BatchUpdate(moved, replacements, false)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package qtree
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokg/pkg/plane"
+	"github.com/kjkrol/goku/pkg/sliceutils"
+)
+
+func TestQuadTree_Loose_StraddlingBoxesDescend(t *testing.T) {
+	strict := NewQuadTree(plane.NewEuclidean2D(64.0, 64.0))
+	defer strict.Close()
+	loose := NewQuadTree(plane.NewEuclidean2D(64.0, 64.0), WithLooseness[float64](2))
+	defer loose.Close()
+
+	// 4x4 boxes centred on the vertical split line of the root
+	for i := range 8 {
+		box := geom.NewAABBAt(geom.NewVec(30.0, float64(i*8)+1), 4, 4)
+		strict.Add(newTestItemFromBox(box))
+		loose.Add(newTestItemFromBox(box))
+	}
+
+	if len(strict.root.items) != 8 {
+		t.Errorf("expected strict tree to keep all straddling boxes in root, got %d", len(strict.root.items))
+	}
+	if len(loose.root.items) != 0 {
+		t.Errorf("expected loose tree to push straddling boxes to children, got %d in root", len(loose.root.items))
+	}
+	for _, child := range loose.root.childs {
+		if !child.loose.Contains(child.bounds) {
+			t.Errorf("loose bounds %v do not enclose quadrant %v", child.loose, child.bounds)
+		}
+		for _, item := range child.allItems() {
+			if !child.loose.Contains(item.Bound()) {
+				t.Errorf("item %v escapes loose bounds %v", item.Bound(), child.loose)
+			}
+		}
+	}
+}
+
+func TestQuadTree_Loose_QueriesMatchStrictTree(t *testing.T) {
+	for _, space := range []plane.Space2D[float64]{
+		plane.NewEuclidean2D(128.0, 128.0),
+		plane.NewToroidal2D(128.0, 128.0),
+	} {
+		t.Run(space.Name(), func(t *testing.T) {
+			strict := NewQuadTree(space)
+			defer strict.Close()
+			loose := NewQuadTree(space, WithLooseness[float64](2))
+			defer loose.Close()
+
+			rnd := rand.New(rand.NewSource(32))
+			items := addRandomBoxes(strict, rnd, 400)
+			for _, item := range items {
+				if !loose.Add(item) {
+					t.Fatalf("loose tree rejected %v", item.Bound())
+				}
+			}
+
+			for _, item := range items[:100] {
+				if !loose.Remove(item) || !strict.Remove(item) {
+					t.Fatalf("failed to remove %v", item.Bound())
+				}
+			}
+			verifySubtreeSizes(t, loose.root)
+
+			for i, target := range items[100:160] {
+				margin := float64(i % 20)
+				expected := strict.FindNeighbors(target, margin)
+				got := loose.FindNeighbors(target, margin)
+				if !sliceutils.SameElements(got, expected) {
+					t.Errorf("loose FindNeighbors %v differs from strict %v", got, expected)
+				}
+				if count := loose.CountNeighbors(target, margin); count != len(expected) {
+					t.Errorf("loose CountNeighbors = %d, expected %d", count, len(expected))
+				}
+				area := geom.NewAABBAt(target.Bound().TopLeft, margin*2, margin)
+				if got, expected := loose.CountInAABB(area), strict.CountInAABB(area); got != expected {
+					t.Errorf("loose CountInAABB(%v) = %d, expected %d", area, got, expected)
+				}
+			}
+		})
+	}
+}
@@ -1,82 +1,181 @@
 package qtree
 
 import (
+	"context"
+	"iter"
+	"slices"
+
 	"github.com/kjkrol/gokg/pkg/geom"
 	"github.com/kjkrol/gokq/pkg/dfs"
 )
 
 type Node[T geom.Numeric] struct {
-	bounds geom.AABB[T]
-	items  []Item[T]
-	parent *Node[T]
-	childs []*Node[T]
+	bounds  geom.AABB[T]
+	loose   geom.AABB[T]
+	items   []Item[T]
+	parent  *Node[T]
+	childs  []*Node[T]
+	size    int
+	summary any
 }
 
 func newNode[T geom.Numeric](bounds geom.AABB[T], parent *Node[T]) *Node[T] {
-	return &Node[T]{bounds: bounds, items: make([]Item[T], 0), parent: parent}
+	return &Node[T]{bounds: bounds, loose: bounds, items: make([]Item[T], 0), parent: parent}
+}
+
+// ancestorOf reports whether n lies on the path from the root to other,
+// excluding other itself.
+func (n *Node[T]) ancestorOf(other *Node[T]) bool {
+	for node := other.parent; node != nil; node = node.parent {
+		if node == n {
+			return true
+		}
+	}
+	return false
 }
 
 func (n *Node[T]) isLeaf() bool { return len(n.childs) == 0 }
 func (n *Node[T]) isNode() bool { return len(n.childs) > 0 }
 
+// findFittingChild returns the child whose quadrant holds the centre of r and
+// whose loose bounds contain r entirely. Without looseness the loose bounds
+// equal the quadrant, so this reduces to plain containment.
 func (n *Node[T]) findFittingChild(r geom.AABB[T]) *Node[T] {
+	center := geom.NewVec(
+		r.TopLeft.X+(r.BottomRight.X-r.TopLeft.X)/2,
+		r.TopLeft.Y+(r.BottomRight.Y-r.TopLeft.Y)/2,
+	)
 	for _, child := range n.childs {
-		if child.bounds.Contains(r) {
+		if child.loose.Contains(r) && child.bounds.IntersectsVec(center) {
 			return child
 		}
 	}
 	return nil
 }
 
+// countSame reports how many items sharing target's identity are stored on the
+// path that target's bounds would follow from n downwards. Placements are left
+// to countPlaced, as they follow the paths of their own bounds.
+func (n *Node[T]) countSame(target Item[T]) int {
+	count := 0
+	for node := n; node != nil; node = node.findFittingChild(target.Bound()) {
+		for _, item := range node.items {
+			if _, placed := item.(*placement[T]); !placed && sameItem(item, target) {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// countPlaced reports how many of placements are stored below n, each looked
+// up on the path its bounds would follow from n downwards.
+func (n *Node[T]) countPlaced(placements []*placement[T]) int {
+	count := 0
+	for _, p := range placements {
+		for node := n; node != nil; node = node.findFittingChild(p.bound) {
+			if slices.Contains(node.items, Item[T](p)) {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
+
+func (n *Node[T]) resize(delta int) {
+	for node := n; node != nil; node = node.parent {
+		node.size += delta
+	}
+}
+
 func (n *Node[T]) Children() []*Node[T] {
 	return n.childs
 }
 
-func (n *Node[T]) close() {
-	for _, child := range n.childs {
-		child.close()
+// Bounds returns the quadrant assigned to the node.
+func (n *Node[T]) Bounds() geom.AABB[T] {
+	return n.bounds
+}
+
+// LooseBounds returns the area every item of the subtree lies in. It equals
+// Bounds unless the tree was built WithLooseness.
+func (n *Node[T]) LooseBounds() geom.AABB[T] {
+	return n.loose
+}
+
+// Items iterates over the items stored directly in the node, excluding those
+// held by its descendants. An item wrapped into several fragments is yielded
+// only by the node holding its first fragment.
+func (n *Node[T]) Items() iter.Seq[Item[T]] {
+	return func(yield func(Item[T]) bool) {
+		for _, item := range n.items {
+			if duplicateEntry(item) {
+				continue
+			}
+			if !yield(original(item)) {
+				return
+			}
+		}
 	}
-	n.items = nil
-	n.childs = nil
-	n.parent = nil
 }
 
-func (n *Node[T]) allItems() []Item[T] {
-	items := []Item[T]{}
+// Size returns the number of items stored in the node and all its descendants.
+func (n *Node[T]) Size() int {
+	return n.size
+}
 
-	dfs.DFS(n, struct{}{}, func(node *Node[T], _ struct{}) (dfs.DFSControl, struct{}) {
-		items = append(items, node.items...)
-		return dfs.DFSControl{}, struct{}{}
+func (n *Node[T]) close() {
+	dfs.DFSPostOrder(n, func(node *Node[T]) dfs.DFSControl {
+		node.items = nil
+		node.childs = nil
+		node.parent = nil
+		node.summary = nil
+		return dfs.Continue
 	})
+}
 
+func (n *Node[T]) allItems() []Item[T] {
+	items := dfs.Fold(n, make([]Item[T], 0, n.size), func(items []Item[T], node *Node[T]) []Item[T] {
+		return append(items, node.items...)
+	})
 	sortItems(items)
 	return items
 }
 
-func (n *Node[T]) depth() int {
-	maxDepth := 0
+func (n *Node[T]) allItemsCtx(ctx context.Context) ([]Item[T], error) {
+	items := []Item[T]{}
+	if err := dfs.DFSContext(ctx, n, struct{}{}, collectItemsStep(&items)); err != nil {
+		return nil, err
+	}
+	sortItems(items)
+	return items, nil
+}
 
-	dfs.DFS(n, 0, func(node *Node[T], acc int) (dfs.DFSControl, int) {
-		acc = acc + 1
-		if acc > maxDepth {
-			maxDepth = acc
-		}
-		return dfs.DFSControl{}, acc
-	})
+func collectItemsStep[T geom.Numeric](items *[]Item[T]) dfs.DFSStepFunc[*Node[T], struct{}] {
+	return func(node *Node[T], _ struct{}) (dfs.DFSControl, struct{}) {
+		*items = append(*items, node.items...)
+		return dfs.Continue, struct{}{}
+	}
+}
 
+// depth returns the number of levels below and including n, like dfs.MaxDepth,
+// but on a pooled traverser so that a warm call does not allocate.
+func (n *Node[T]) depth(depths *dfs.TraverserPool[*Node[T], int]) int {
+	maxDepth := 0
+	depths.DFS(n, 0, func(_ *Node[T], depth int) (dfs.DFSControl, int) {
+		depth++
+		maxDepth = max(maxDepth, depth)
+		return dfs.Continue, depth
+	})
 	return maxDepth
 }
 
 func (n *Node[T]) leafBounds() []geom.AABB[T] {
-	rectangles := []geom.AABB[T]{}
-
-	dfs.DFS(n, struct{}{}, func(node *Node[T], _ struct{}) (dfs.DFSControl, struct{}) {
-		if node.isLeaf() {
-			rectangles = append(rectangles, node.bounds)
-			return dfs.DFSControl{Skip: true}, struct{}{}
-		}
-		return dfs.DFSControl{}, struct{}{}
-	})
-
+	leaves := dfs.Leaves(n)
+	rectangles := make([]geom.AABB[T], len(leaves))
+	for i, leaf := range leaves {
+		rectangles[i] = leaf.bounds
+	}
 	return rectangles
 }
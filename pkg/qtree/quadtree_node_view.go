@@ -0,0 +1,101 @@
+package qtree
+
+import (
+	"iter"
+	"slices"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+)
+
+// NodeView is a read-only handle to a tree node, for custom queries and
+// rendering outside the package. It implements dfs.ChildCarrier, so the dfs
+// and traverse helpers work on it directly. A view is only valid until the
+// tree is modified.
+type NodeView[T geom.Numeric] struct {
+	node *Node[T]
+}
+
+// Root returns a view of the root node.
+func (t *QuadTree[T]) Root() NodeView[T] {
+	return NodeView[T]{node: t.root}
+}
+
+// Bounds returns the quadrant assigned to the node.
+func (v NodeView[T]) Bounds() geom.AABB[T] {
+	return v.node.bounds
+}
+
+// LooseBounds returns the area every item of the subtree lies in. It equals
+// Bounds unless the tree was built WithLooseness.
+func (v NodeView[T]) LooseBounds() geom.AABB[T] {
+	return v.node.loose
+}
+
+// Items iterates over the items stored directly in the node.
+func (v NodeView[T]) Items() iter.Seq[Item[T]] {
+	return v.node.Items()
+}
+
+// Size returns the number of items stored in the node and its descendants.
+func (v NodeView[T]) Size() int {
+	return v.node.size
+}
+
+// Depth returns the distance from the root, which is at depth 0.
+func (v NodeView[T]) Depth() int {
+	depth := 0
+	for node := v.node.parent; node != nil; node = node.parent {
+		depth++
+	}
+	return depth
+}
+
+// IsLeaf reports whether the node has no children.
+func (v NodeView[T]) IsLeaf() bool {
+	return v.node.isLeaf()
+}
+
+// Children returns views of the node's children, indexed by Quadrant.
+func (v NodeView[T]) Children() []NodeView[T] {
+	if v.node.isLeaf() {
+		return nil
+	}
+	children := make([]NodeView[T], len(v.node.childs))
+	for i, child := range v.node.childs {
+		children[i] = NodeView[T]{node: child}
+	}
+	return children
+}
+
+// Parent returns a view of the enclosing node; ok is false for the root.
+func (v NodeView[T]) Parent() (parent NodeView[T], ok bool) {
+	if v.node.parent == nil {
+		return v, false
+	}
+	return NodeView[T]{node: v.node.parent}, true
+}
+
+// Quadrant returns the position of the node within its parent; ok is false
+// for the root.
+func (v NodeView[T]) Quadrant() (q Quadrant, ok bool) {
+	if v.node.parent == nil {
+		return 0, false
+	}
+	return v.node.quadrant(), true
+}
+
+// Path returns the quadrants leading from the root to the node, which print
+// as e.g. "NW.SE"; the root has an empty path.
+func (v NodeView[T]) Path() Path {
+	path := Path{}
+	for node := v.node; node.parent != nil; node = node.parent {
+		path = append(path, node.quadrant())
+	}
+	slices.Reverse(path)
+	return path
+}
+
+// NodeViewSummary is NodeSummary for a node view.
+func NodeViewSummary[T geom.Numeric, A any](view NodeView[T]) (A, bool) {
+	return NodeSummary[T, A](view.node)
+}
@@ -0,0 +1,110 @@
+package qtree
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/kjkrol/gokg/pkg/plane"
+	"github.com/kjkrol/gokq/pkg/dfs"
+)
+
+func TestNodeView_Navigation(t *testing.T) {
+	qtree := NewQuadTree(plane.NewEuclidean2D(64.0, 64.0))
+	defer qtree.Close()
+	addRandomBoxes(qtree, rand.New(rand.NewSource(47)), 200)
+
+	root := qtree.Root()
+	if root.Depth() != 0 || len(root.Path()) != 0 {
+		t.Errorf("unexpected root depth %d, path %q", root.Depth(), root.Path())
+	}
+	if q, ok := root.Quadrant(); ok {
+		t.Errorf("expected the root to have no quadrant, got %v", q)
+	}
+	if _, ok := root.Parent(); ok {
+		t.Error("expected the root to have no parent")
+	}
+	if root.IsLeaf() {
+		t.Fatal("expected the root to be split")
+	}
+
+	children := root.Children()
+	quadrants := root.Bounds().Split()
+	for i, child := range children {
+		if q, ok := child.Quadrant(); !ok || q != Quadrant(i) || !slices.Equal(child.Path(), Path{Quadrant(i)}) {
+			t.Errorf("child %d is in quadrant %v with path %q", i, q, child.Path())
+		}
+		if child.Bounds() != quadrants[i] {
+			t.Errorf("child %v has bounds %v, expected %v", Quadrant(i), child.Bounds(), quadrants[i])
+		}
+		if parent, ok := child.Parent(); !ok || parent != root {
+			t.Errorf("child %v does not lead back to the root", Quadrant(i))
+		}
+	}
+
+	dfs.DFS(root, struct{}{}, func(view NodeView[float64], _ struct{}) (dfs.DFSControl, struct{}) {
+		if view.Depth() != len(view.Path()) {
+			t.Errorf("node %q reports depth %d, expected %d", view.Path(), view.Depth(), len(view.Path()))
+		}
+		size := 0
+		for range view.Items() {
+			size++
+		}
+		for _, child := range view.Children() {
+			size += child.Size()
+		}
+		if size != view.Size() {
+			t.Errorf("node %q has size %d, its items and children hold %d", view.Path(), view.Size(), size)
+		}
+		return dfs.Continue, struct{}{}
+	})
+
+	if leaves := dfs.Leaves(root); len(leaves) != len(qtree.LeafBounds()) {
+		t.Errorf("expected %d leaves, got %d", len(qtree.LeafBounds()), len(leaves))
+	}
+}
+
+func TestQuadTree_VisitNodes_ReportsEveryNode(t *testing.T) {
+	qtree := NewQuadTree(plane.NewToroidal2D(64.0, 64.0))
+	defer qtree.Close()
+	addRandomBoxes(qtree, rand.New(rand.NewSource(48)), 200)
+
+	visited := []string{}
+	qtree.VisitNodes(func(view NodeView[float64]) bool {
+		visited = append(visited, view.Path().String())
+		return true
+	})
+
+	expected := []string{}
+	for _, view := range dfs.Collect(qtree.Root(), func(NodeView[float64]) bool { return true }) {
+		expected = append(expected, view.Path().String())
+	}
+	slices.Sort(visited)
+	slices.Sort(expected)
+	if !slices.Equal(visited, expected) {
+		t.Errorf("VisitNodes reported %v, expected %v", visited, expected)
+	}
+}
+
+func ExampleQuadTree_VisitNodes() {
+	qtree := NewQuadTree(plane.NewEuclidean2D(16.0, 16.0))
+	defer qtree.Close()
+
+	for _, pos := range [][2]float64{{1, 1}, {3, 1}, {1, 3}, {3, 3}, {13, 13}} {
+		qtree.Add(newTestItemPointAtPos(pos[0], pos[1]))
+	}
+
+	qtree.VisitNodes(func(node NodeView[float64]) bool {
+		if node.Size() == 0 {
+			return false
+		}
+		fmt.Printf("%q depth=%d size=%d leaf=%v\n", node.Path(), node.Depth(), node.Size(), node.IsLeaf())
+		return true
+	})
+
+	// Output:
+	// "" depth=0 size=5 leaf=false
+	// "SE" depth=1 size=1 leaf=true
+	// "NW" depth=1 size=4 leaf=true
+}
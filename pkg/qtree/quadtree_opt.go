@@ -12,6 +12,46 @@ func WithMaxDepth[T geom.Numeric](depth int) QuadTreeOption[T] {
 	}
 }
 
+// WithLooseness turns the tree into a loose quadtree: every child quadrant is
+// enlarged around its centre by factor (e.g. 2 doubles its width and height),
+// and items descend into the quadrant holding their centre as long as they fit
+// the enlarged bounds. Boxes straddling quadrant edges then no longer pile up
+// in internal nodes. Factors up to 1 keep the default, strict placement.
+func WithLooseness[T geom.Numeric](factor float64) QuadTreeOption[T] {
+	return func(qt *QuadTree[T]) {
+		qt.appender.looseness = factor
+	}
+}
+
+// WithHilbertOrder makes AddAll insert and AllItems return items along the
+// Hilbert curve through their centres, so tree dumps and loops over them walk
+// space with good locality.
+func WithHilbertOrder[T geom.Numeric]() QuadTreeOption[T] {
+	return func(qt *QuadTree[T]) {
+		qt.hilbert = true
+	}
+}
+
+// WithParallelThreshold sets the number of stored items from which AllItems,
+// Count, Stats and CollidingPairs run on several goroutines; the default is
+// PARALLEL_THRESHOLD.
+func WithParallelThreshold[T geom.Numeric](items int) QuadTreeOption[T] {
+	return func(qt *QuadTree[T]) {
+		if items > 0 {
+			qt.parallelThreshold = items
+		}
+	}
+}
+
+// WithOutOfBoundsPolicy sets how Add, AddAll and BatchUpdate handle items
+// whose bounds are not contained in the plane viewport; the default is
+// RejectOutOfBounds.
+func WithOutOfBoundsPolicy[T geom.Numeric](policy OutOfBoundsPolicy) QuadTreeOption[T] {
+	return func(qt *QuadTree[T]) {
+		qt.boundsPolicy = policy
+	}
+}
+
 func WithBatchCompressThreshold[T geom.Numeric](threshold int) QuadTreeOption[T] {
 	return func(qt *QuadTree[T]) {
 		if threshold > 0 {
@@ -19,3 +59,13 @@ func WithBatchCompressThreshold[T geom.Numeric](threshold int) QuadTreeOption[T]
 		}
 	}
 }
+
+// WithAggregator attaches a per-node summary maintained on every insertion,
+// removal and compression. Summaries are read back with NodeSummary.
+func WithAggregator[T geom.Numeric, A any](aggregator Aggregator[T, A]) QuadTreeOption[T] {
+	return func(qt *QuadTree[T]) {
+		erased := typedAggregator[T, A]{aggregator}
+		qt.appender.aggregator = erased
+		qt.remover.aggregator = erased
+	}
+}
@@ -0,0 +1,214 @@
+package qtree
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokq/pkg/dfs"
+)
+
+// PARALLEL_THRESHOLD is the default number of stored items from which
+// whole-tree operations such as AllItems, Stats and CollidingPairs walk the
+// tree with dfs.DFSParallel.
+const PARALLEL_THRESHOLD int = 1 << 14
+
+// QuadTreeStats summarizes the shape of a tree.
+type QuadTreeStats struct {
+	Nodes        int // all nodes, including the root
+	Leaves       int // nodes without children
+	Items        int // stored entries; a wrapped item counts once per fragment
+	MaxNodeItems int // largest number of items held directly by a single node
+}
+
+func (s QuadTreeStats) merge(o QuadTreeStats) QuadTreeStats {
+	return QuadTreeStats{
+		Nodes:        s.Nodes + o.Nodes,
+		Leaves:       s.Leaves + o.Leaves,
+		Items:        s.Items + o.Items,
+		MaxNodeItems: max(s.MaxNodeItems, o.MaxNodeItems),
+	}
+}
+
+// Stats reports node and item counts of the tree.
+func (t *QuadTree[T]) Stats() QuadTreeStats {
+	return foldTree[T, QuadTreeStats](t, QuadTreeStats{}, statsFold[T]{})
+}
+
+// CollidingPairs returns every pair of distinct items whose bounds touch or
+// overlap on the plane, i.e. the pairs FindNeighbors reports for margin 0. Each
+// pair is reported once, with items and pairs in the order of AllItems.
+//
+// A single pass over the nodes tests every stored item against the items after
+// it in its node and against the items below it. An item can only meet items
+// in other subtrees when it reaches the edge of its node or the node is loose,
+// and only then is the rest of the tree searched as well.
+func (t *QuadTree[T]) CollidingPairs() [][2]Item[T] {
+	items := t.AllItems()
+	order := make(map[Item[T]]int, len(items))
+	for i, item := range items {
+		order[item] = i
+	}
+
+	fold := collisionFold[T]{t: t, order: order, distance: wrappedDistance(t.space)}
+	pairs := foldTree[T, [][2]Item[T]](t, nil, fold)
+	for _, item := range t.overflow {
+		for _, other := range t.FindNeighbors(item, 0) {
+			pairs = append(pairs, fold.pair(item, other))
+		}
+	}
+
+	slices.SortFunc(pairs, func(a, b [2]Item[T]) int {
+		return cmp.Or(cmp.Compare(order[a[0]], order[b[0]]), cmp.Compare(order[a[1]], order[b[1]]))
+	})
+	if t.hasOutOfBounds() {
+		// Fragments of a wrapped item and pairs of overflow items are met
+		// more than once.
+		pairs = slices.Compact(pairs)
+	}
+	return pairs
+}
+
+// parallel reports whether the tree is large enough for whole-tree operations
+// to run concurrently.
+func (t *QuadTree[T]) parallel() bool {
+	return t.root.size >= t.parallelThreshold
+}
+
+func (t *QuadTree[T]) parallelItems() []Item[T] {
+	items := foldTree[T, []Item[T]](t, nil, itemsFold[T]{})
+	sortItems(items)
+	return items
+}
+
+// nodeFold folds the nodes of a tree into a result: step folds one node into
+// the result of a worker and merge combines the results of two workers.
+type nodeFold[T geom.Numeric, R any] interface {
+	step(node *Node[T], result R) (dfs.DFSControl, R)
+	merge(a, b R) R
+}
+
+// foldTree folds every node of the tree into a result, on several goroutines
+// when the tree is large and on a pooled traverser otherwise. Only the parallel
+// path turns fold into closures, so a warmed sequential fold does not allocate.
+func foldTree[T geom.Numeric, R any](t *QuadTree[T], identity R, fold nodeFold[T, R]) R {
+	if t.parallel() {
+		return dfs.DFSParallel(t.root, identity, fold.step, fold.merge)
+	}
+	result := identity
+	t.traversers.DFS(t.root, struct{}{}, func(node *Node[T], _ struct{}) (dfs.DFSControl, struct{}) {
+		var control dfs.DFSControl
+		control, result = fold.step(node, result)
+		return control, struct{}{}
+	})
+	return result
+}
+
+type statsFold[T geom.Numeric] struct{}
+
+func (statsFold[T]) step(node *Node[T], stats QuadTreeStats) (dfs.DFSControl, QuadTreeStats) {
+	stats.Nodes++
+	if node.isLeaf() {
+		stats.Leaves++
+	}
+	stats.Items += len(node.items)
+	stats.MaxNodeItems = max(stats.MaxNodeItems, len(node.items))
+	return dfs.Continue, stats
+}
+
+func (statsFold[T]) merge(a, b QuadTreeStats) QuadTreeStats { return a.merge(b) }
+
+type itemsFold[T geom.Numeric] struct{}
+
+func (itemsFold[T]) step(node *Node[T], items []Item[T]) (dfs.DFSControl, []Item[T]) {
+	return dfs.Continue, append(items, node.items...)
+}
+
+func (itemsFold[T]) merge(a, b []Item[T]) []Item[T] { return append(a, b...) }
+
+// collisionFold collects the pairs CollidingPairs reports for the items stored
+// in each node, using order to orient every pair.
+type collisionFold[T geom.Numeric] struct {
+	t        *QuadTree[T]
+	order    map[Item[T]]int
+	distance func(a, b geom.AABB[T]) T
+}
+
+func (f collisionFold[T]) step(node *Node[T], pairs [][2]Item[T]) (dfs.DFSControl, [][2]Item[T]) {
+	for i, entry := range node.items {
+		for _, other := range node.items[i+1:] {
+			if f.collide(entry, other) {
+				pairs = append(pairs, f.pair(entry, other))
+			}
+		}
+
+		reaches := f.t.counter.strategy.NodeIntersectionDetectionFactory(entry, 0)
+		f.t.traversers.DFS(node, struct{}{}, func(n *Node[T], _ struct{}) (dfs.DFSControl, struct{}) {
+			if n == node {
+				return dfs.Continue, struct{}{}
+			}
+			if !reaches(*n) {
+				return dfs.SkipChildren, struct{}{}
+			}
+			pairs = f.appendCollisions(pairs, entry, n.items, false)
+			return dfs.Continue, struct{}{}
+		})
+
+		if node.parent == nil || !f.crosses(node, entry) {
+			continue
+		}
+		// Both items of a pair spanning two subtrees reach the edge of their
+		// nodes, so each finds the other and the earlier one reports it.
+		f.t.traversers.DFS(f.t.root, struct{}{}, func(n *Node[T], _ struct{}) (dfs.DFSControl, struct{}) {
+			if n == node || !reaches(*n) {
+				return dfs.SkipChildren, struct{}{}
+			}
+			if !n.ancestorOf(node) {
+				pairs = f.appendCollisions(pairs, entry, n.items, true)
+			}
+			return dfs.Continue, struct{}{}
+		})
+	}
+	return dfs.Continue, pairs
+}
+
+func (collisionFold[T]) merge(a, b [][2]Item[T]) [][2]Item[T] { return append(a, b...) }
+
+// appendCollisions appends the pairs entry forms with the colliding items of
+// others; with ordered set only the pairs in which entry comes first.
+func (f collisionFold[T]) appendCollisions(pairs [][2]Item[T], entry Item[T], others []Item[T], ordered bool) [][2]Item[T] {
+	for _, other := range others {
+		if !f.collide(entry, other) {
+			continue
+		}
+		if ordered && f.order[original(other)] < f.order[original(entry)] {
+			continue
+		}
+		pairs = append(pairs, f.pair(entry, other))
+	}
+	return pairs
+}
+
+// crosses reports whether entry may collide with items outside the subtree
+// and the ancestors of node: the node is loose, or entry reaches its edge.
+func (f collisionFold[T]) crosses(node *Node[T], entry Item[T]) bool {
+	if node.loose != node.bounds {
+		return true
+	}
+	box := entry.Bound()
+	return box.TopLeft.X <= node.bounds.TopLeft.X || box.TopLeft.Y <= node.bounds.TopLeft.Y ||
+		box.BottomRight.X >= node.bounds.BottomRight.X || box.BottomRight.Y >= node.bounds.BottomRight.Y
+}
+
+func (f collisionFold[T]) collide(a, b Item[T]) bool {
+	return !original(a).SameID(original(b)) && f.distance(a.Bound(), b.Bound()) <= 0
+}
+
+// pair returns the items entries a and b stand for, in the order of AllItems.
+func (f collisionFold[T]) pair(a, b Item[T]) [2]Item[T] {
+	a, b = original(a), original(b)
+	if f.order[b] < f.order[a] {
+		a, b = b, a
+	}
+	return [2]Item[T]{a, b}
+}
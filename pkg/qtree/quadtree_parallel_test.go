@@ -0,0 +1,156 @@
+package qtree
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokg/pkg/plane"
+	"github.com/kjkrol/goku/pkg/sliceutils"
+)
+
+func TestQuadTree_ParallelOperations_MatchSequential(t *testing.T) {
+	for _, space := range []plane.Space2D[float64]{
+		plane.NewEuclidean2D(128.0, 128.0),
+		plane.NewToroidal2D(128.0, 128.0),
+	} {
+		t.Run(space.Name(), func(t *testing.T) {
+			sequential := NewQuadTree(space)
+			defer sequential.Close()
+			parallel := NewQuadTree(space, WithParallelThreshold[float64](1))
+			defer parallel.Close()
+
+			items := addRandomBoxes(sequential, rand.New(rand.NewSource(42)), 500)
+			for _, item := range items {
+				parallel.Add(item)
+			}
+
+			if got, expected := parallel.Count(), sequential.Count(); got != expected || got != len(items) {
+				t.Errorf("Count() = %d, expected %d", got, expected)
+			}
+			if got, expected := parallel.AllItems(), sequential.AllItems(); !sliceutils.SameElements(got, expected) {
+				t.Errorf("AllItems() = %v, expected %v", got, expected)
+			}
+			if got, expected := parallel.Stats(), sequential.Stats(); got != expected {
+				t.Errorf("Stats() = %+v, expected %+v", got, expected)
+			}
+			if got, expected := parallel.CollidingPairs(), sequential.CollidingPairs(); !sliceutils.SameElements(got, expected) {
+				t.Errorf("CollidingPairs() = %v, expected %v", got, expected)
+			}
+		})
+	}
+}
+
+func TestQuadTree_Stats(t *testing.T) {
+	qtree := NewQuadTree(plane.NewEuclidean2D(128.0, 128.0))
+	defer qtree.Close()
+
+	items := addRandomBoxes(qtree, rand.New(rand.NewSource(43)), 200)
+	stats := qtree.Stats()
+
+	if stats.Items != len(items) {
+		t.Errorf("expected %d items, got %d", len(items), stats.Items)
+	}
+	if stats.Leaves != len(qtree.LeafBounds()) {
+		t.Errorf("expected %d leaves, got %d", len(qtree.LeafBounds()), stats.Leaves)
+	}
+	if stats.Nodes != 1+(stats.Nodes-stats.Leaves)*4 {
+		t.Errorf("expected every internal node to have 4 children, got %+v", stats)
+	}
+	if stats.MaxNodeItems == 0 || stats.MaxNodeItems > stats.Items {
+		t.Errorf("unexpected MaxNodeItems in %+v", stats)
+	}
+}
+
+func TestQuadTree_CollidingPairs_MatchesBruteForce(t *testing.T) {
+	for _, space := range []plane.Space2D[float64]{
+		plane.NewEuclidean2D(128.0, 128.0),
+		plane.NewToroidal2D(128.0, 128.0),
+	} {
+		t.Run(space.Name(), func(t *testing.T) {
+			qtree := NewQuadTree(space, WithParallelThreshold[float64](1))
+			defer qtree.Close()
+
+			addRandomBoxes(qtree, rand.New(rand.NewSource(44)), 400)
+			items := qtree.AllItems()
+			distance := space.AABBDistance()
+
+			expected := [][2]Item[float64]{}
+			for i, a := range items {
+				for _, b := range items[i+1:] {
+					if distance(a.Bound(), b.Bound()) <= 0 {
+						expected = append(expected, [2]Item[float64]{a, b})
+					}
+				}
+			}
+
+			got := qtree.CollidingPairs()
+			if len(expected) == 0 {
+				t.Fatal("expected the random boxes to collide")
+			}
+			if !sliceutils.SameElements(got, expected) {
+				t.Errorf("CollidingPairs() = %v, expected %v", got, expected)
+			}
+		})
+	}
+}
+
+// Boxes with integer corners often end exactly on a quadrant edge, so pairs
+// spanning two subtrees, the seam of a toroidal plane and loose or wrapped
+// placements all come up.
+func TestQuadTree_CollidingPairs_MatchesFindNeighbors(t *testing.T) {
+	euclidean := plane.NewEuclidean2D(128.0, 128.0)
+	toroidal := plane.NewToroidal2D(128.0, 128.0)
+	tests := []struct {
+		name  string
+		space plane.Space2D[float64]
+		opts  []QuadTreeOption[float64]
+		spill float64
+	}{
+		{name: "Euclidean", space: euclidean},
+		{name: "Toroidal", space: toroidal},
+		{name: "Loose", space: euclidean, opts: []QuadTreeOption[float64]{WithLooseness[float64](2)}},
+		{name: "Wrap", space: toroidal, opts: []QuadTreeOption[float64]{WithOutOfBoundsPolicy[float64](WrapOutOfBounds)}, spill: 8},
+		{name: "Overflow", space: euclidean, opts: []QuadTreeOption[float64]{WithOutOfBoundsPolicy[float64](OverflowOutOfBounds)}, spill: 8},
+	}
+	for _, tt := range tests {
+		for _, threshold := range []int{PARALLEL_THRESHOLD, 1} {
+			qtree := NewQuadTree(tt.space, append(tt.opts, WithParallelThreshold[float64](threshold))...)
+			rnd := rand.New(rand.NewSource(42))
+			for range 300 {
+				w, h := float64(1+rnd.Intn(8)), float64(1+rnd.Intn(8))
+				x := float64(rnd.Intn(int(128 - w + tt.spill)))
+				y := float64(rnd.Intn(int(128 - h + tt.spill)))
+				if !qtree.Add(newTestItemFromBox(geom.NewAABBAt(geom.NewVec(x, y), w, h))) {
+					t.Fatalf("%s: Add at (%v, %v) failed", tt.name, x, y)
+				}
+			}
+
+			items := qtree.AllItems()
+			order := make(map[Item[float64]]int, len(items))
+			for i, item := range items {
+				order[item] = i
+			}
+			seen := map[[2]Item[float64]]bool{}
+			expected := [][2]Item[float64]{}
+			for _, item := range items {
+				for _, other := range qtree.FindNeighbors(item, 0) {
+					pair := [2]Item[float64]{item, other}
+					if order[other] < order[item] {
+						pair = [2]Item[float64]{other, item}
+					}
+					if !seen[pair] {
+						seen[pair] = true
+						expected = append(expected, pair)
+					}
+				}
+			}
+
+			got := qtree.CollidingPairs()
+			if len(got) != len(expected) || !sliceutils.SameElements(got, expected) {
+				t.Errorf("%s/threshold %d: CollidingPairs() returned %d pairs, expected %d", tt.name, threshold, len(got), len(expected))
+			}
+			qtree.Close()
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package qtree
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Quadrant identifies a child of a split node. The values follow the order of
+// geom.AABB.Split, which is also the order of Node.Children.
+type Quadrant uint8
+
+const (
+	NW Quadrant = iota
+	NE
+	SW
+	SE
+)
+
+// QUADRANTS is the number of children of a split node.
+const QUADRANTS int = 4
+
+var quadrantNames = [QUADRANTS]string{"NW", "NE", "SW", "SE"}
+
+// ErrInvalidQuadrant is returned when parsing an unknown quadrant name.
+var ErrInvalidQuadrant = errors.New("qtree: invalid quadrant")
+
+func (q Quadrant) String() string {
+	if int(q) < QUADRANTS {
+		return quadrantNames[q]
+	}
+	return fmt.Sprintf("Quadrant(%d)", uint8(q))
+}
+
+// ParseQuadrant returns the quadrant named s, one of "NW", "NE", "SW" and "SE".
+func ParseQuadrant(s string) (Quadrant, error) {
+	for q, name := range quadrantNames {
+		if s == name {
+			return Quadrant(q), nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", ErrInvalidQuadrant, s)
+}
+
+// Path addresses a node by the quadrants leading to it from the root; the
+// empty path is the root. Paths print and parse as dot-separated quadrant
+// names, e.g. "NW.SE".
+type Path []Quadrant
+
+func (p Path) String() string {
+	names := make([]string, len(p))
+	for i, q := range p {
+		names[i] = q.String()
+	}
+	return strings.Join(names, ".")
+}
+
+// ParsePath parses a path printed by Path.String; "" is the root.
+func ParsePath(s string) (Path, error) {
+	if s == "" {
+		return Path{}, nil
+	}
+	names := strings.Split(s, ".")
+	path := make(Path, len(names))
+	for i, name := range names {
+		q, err := ParseQuadrant(name)
+		if err != nil {
+			return nil, fmt.Errorf("path %q: %w", s, err)
+		}
+		path[i] = q
+	}
+	return path, nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (p Path) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (p *Path) UnmarshalText(text []byte) error {
+	path, err := ParsePath(string(text))
+	if err != nil {
+		return err
+	}
+	*p = path
+	return nil
+}
+
+// Child returns the child in quadrant q, or nil when the node is a leaf.
+func (n *Node[T]) Child(q Quadrant) *Node[T] {
+	if n.isLeaf() || int(q) >= len(n.childs) {
+		return nil
+	}
+	return n.childs[q]
+}
+
+// quadrant returns the position of a non-root node within its parent.
+func (n *Node[T]) quadrant() Quadrant {
+	for q, child := range n.parent.childs {
+		if child == n {
+			return Quadrant(q)
+		}
+	}
+	panic("qtree: node is not a child of its parent")
+}
+
+// NodeAt returns the node addressed by path; ok is false when the path leads
+// below a leaf.
+func (t *QuadTree[T]) NodeAt(path Path) (view NodeView[T], ok bool) {
+	node := t.root
+	for _, q := range path {
+		if node = node.Child(q); node == nil {
+			return NodeView[T]{}, false
+		}
+	}
+	return NodeView[T]{node: node}, true
+}
+
+// PathOf returns the path of the node storing item; ok is false when the item
+// is not in the tree. The item is looked up by its current bounds, so it must
+// not have moved since it was added. For an item placed by the
+// OutOfBoundsPolicy the node holding its first fragment is reported; items in
+// the overflow bucket have no path.
+func (t *QuadTree[T]) PathOf(item Item[T]) (path Path, ok bool) {
+	if path, ok := t.root.pathOf(item); ok {
+		return path, true
+	}
+	if placed := t.placed[item]; len(placed) > 0 {
+		return t.root.pathOf(placed[len(placed)-1].first)
+	}
+	return nil, false
+}
+
+func (n *Node[T]) pathOf(entry Item[T]) (path Path, ok bool) {
+	path = Path{}
+	for node := n; node != nil; node = node.findFittingChild(entry.Bound()) {
+		if node.parent != nil {
+			path = append(path, node.quadrant())
+		}
+		for _, it := range node.items {
+			if it == entry {
+				return path, true
+			}
+		}
+	}
+	return nil, false
+}
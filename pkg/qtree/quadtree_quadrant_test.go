@@ -0,0 +1,140 @@
+package qtree
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/kjkrol/gokg/pkg/plane"
+)
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Path
+		wantErr bool
+	}{
+		{input: "", want: Path{}},
+		{input: "NW", want: Path{NW}},
+		{input: "NW.SE", want: Path{NW, SE}},
+		{input: "SE.SW.NE.NW", want: Path{SE, SW, NE, NW}},
+		{input: "nw", wantErr: true},
+		{input: "NW.", wantErr: true},
+		{input: ".NW", wantErr: true},
+		{input: "NW.XX", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParsePath(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidQuadrant) {
+					t.Fatalf("ParsePath(%q) error = %v, expected ErrInvalidQuadrant", tt.input, err)
+				}
+				return
+			}
+			if err != nil || !slices.Equal(got, tt.want) {
+				t.Fatalf("ParsePath(%q) = %v, %v; expected %v", tt.input, got, err, tt.want)
+			}
+			if got.String() != tt.input {
+				t.Errorf("ParsePath(%q).String() = %q", tt.input, got.String())
+			}
+		})
+	}
+}
+
+func TestQuadrant_String(t *testing.T) {
+	for q, want := range map[Quadrant]string{NW: "NW", NE: "NE", SW: "SW", SE: "SE", Quadrant(7): "Quadrant(7)"} {
+		if got := q.String(); got != want {
+			t.Errorf("Quadrant(%d).String() = %q, expected %q", uint8(q), got, want)
+		}
+	}
+}
+
+func TestPath_JSON(t *testing.T) {
+	data, err := json.Marshal(map[string]Path{"node": {NE, SW}})
+	if err != nil || string(data) != `{"node":"NE.SW"}` {
+		t.Fatalf("json.Marshal = %s, %v", data, err)
+	}
+
+	var decoded map[string]Path
+	if err := json.Unmarshal(data, &decoded); err != nil || !slices.Equal(decoded["node"], Path{NE, SW}) {
+		t.Fatalf("json.Unmarshal = %v, %v", decoded, err)
+	}
+	if err := json.Unmarshal([]byte(`{"node":"NE.XX"}`), &decoded); !errors.Is(err, ErrInvalidQuadrant) {
+		t.Errorf("expected ErrInvalidQuadrant, got %v", err)
+	}
+}
+
+func TestQuadTree_NodeAt_PathOf(t *testing.T) {
+	for _, space := range []plane.Space2D[float64]{
+		plane.NewEuclidean2D(64.0, 64.0),
+		plane.NewToroidal2D(64.0, 64.0),
+	} {
+		t.Run(space.Name(), func(t *testing.T) {
+			qtree := NewQuadTree(space)
+			defer qtree.Close()
+			items := addRandomBoxes(qtree, rand.New(rand.NewSource(48)), 200)
+
+			for _, item := range items {
+				path, ok := qtree.PathOf(item)
+				if !ok {
+					t.Fatalf("PathOf(%v) found nothing", item)
+				}
+				view, ok := qtree.NodeAt(path)
+				if !ok {
+					t.Fatalf("NodeAt(%q) found nothing", path)
+				}
+				if !slices.Equal(view.Path(), path) {
+					t.Errorf("NodeAt(%q) returned node %q", path, view.Path())
+				}
+				if !slices.ContainsFunc(slices.Collect(view.Items()), func(it Item[float64]) bool { return it == item }) {
+					t.Errorf("node %q does not hold %v", path, item)
+				}
+			}
+
+			if _, ok := qtree.PathOf(newTestItemFromPos(1.0, 1.0, 1.0, 1.0)); ok {
+				t.Error("expected PathOf to miss an item that was never added")
+			}
+		})
+	}
+}
+
+func TestQuadTree_NodeAt_BelowLeaf(t *testing.T) {
+	qtree := NewQuadTree(plane.NewEuclidean2D(64.0, 64.0))
+	defer qtree.Close()
+
+	if root, ok := qtree.NodeAt(Path{}); !ok || root != qtree.Root() {
+		t.Errorf("expected the empty path to address the root")
+	}
+	if _, ok := qtree.NodeAt(Path{NW}); ok {
+		t.Errorf("expected no child below an unsplit root")
+	}
+	if child := qtree.root.Child(SE); child != nil {
+		t.Errorf("expected Child on a leaf to return nil, got %v", child.Bounds())
+	}
+}
+
+func ExampleQuadTree_NodeAt() {
+	qtree := NewQuadTree(plane.NewEuclidean2D(16.0, 16.0))
+	defer qtree.Close()
+
+	for _, pos := range [][2]float64{{1, 1}, {3, 1}, {1, 3}, {3, 3}, {13, 13}} {
+		qtree.Add(newTestItemPointAtPos(pos[0], pos[1]))
+	}
+
+	path, _ := ParsePath("SE")
+	node, _ := qtree.NodeAt(path)
+	fmt.Println(node.Path(), node.Bounds(), node.Size())
+
+	corner := newTestItemPointAtPos(15.0, 1.0)
+	qtree.Add(corner)
+	path, _ = qtree.PathOf(corner)
+	fmt.Println(path)
+
+	// Output:
+	// SE {(8,8) (16,16)} 1
+	// NE
+}
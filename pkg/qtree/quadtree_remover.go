@@ -6,7 +6,9 @@ import (
 )
 
 type QuadTreeRemover[T geom.Numeric] struct {
-	capacity int
+	capacity   int
+	aggregator nodeAggregator[T]
+	traversers *dfs.TraverserPool[*Node[T], struct{}]
 }
 
 func (qr QuadTreeRemover[T]) remove(node *Node[T], item Item[T]) bool {
@@ -25,6 +27,8 @@ func (qr QuadTreeRemover[T]) removeInternal(node *Node[T], item Item[T]) (*Node[
 	if node.isNode() {
 		if child := node.findFittingChild(item.Bound()); child != nil {
 			if removedNode, ok := qr.removeInternal(child, item); ok {
+				node.size--
+				refreshSummary(qr.aggregator, node)
 				return removedNode, true
 			}
 		}
@@ -33,6 +37,8 @@ func (qr QuadTreeRemover[T]) removeInternal(node *Node[T], item Item[T]) (*Node[
 	for i, it := range node.items {
 		if it == item {
 			node.items = append(node.items[:i], node.items[i+1:]...)
+			node.size--
+			refreshSummary(qr.aggregator, node)
 			return node, true
 		}
 	}
@@ -40,29 +46,30 @@ func (qr QuadTreeRemover[T]) removeInternal(node *Node[T], item Item[T]) (*Node[
 	return nil, false
 }
 
+// compressPath turns the highest node on the path from node to the root whose
+// subtree holds no more than capacity items into a leaf. Subtree sizes only
+// grow towards the root, so that node is found from the sizes alone and its
+// subtree is collected once.
 func (qr QuadTreeRemover[T]) compressPath(node *Node[T]) {
-	for n := node; n != nil; n = n.parent {
-		qr.tryCompress(n)
+	var top *Node[T]
+	for n := node; n != nil && n.size <= qr.capacity; n = n.parent {
+		top = n
 	}
-}
-
-func (qr QuadTreeRemover[T]) tryCompress(node *Node[T]) {
-	if !node.isNode() {
-		return
-	}
-
-	collected := qr.collectItems(node)
-	if len(collected) <= qr.capacity {
-		node.items = collected
-		node.childs = nil
+	if top != nil && top.isNode() {
+		qr.collapse(top)
 	}
 }
 
-func (qr QuadTreeRemover[T]) collectItems(n *Node[T]) []Item[T] {
-	items := make([]Item[T], 0, len(n.items))
-	dfs.DFS(n, struct{}{}, func(node *Node[T], _ struct{}) (dfs.DFSControl, struct{}) {
-		items = append(items, node.items...)
-		return dfs.DFSControl{}, struct{}{}
+// collapse moves every item of the subtree into node and drops its
+// descendants, children before their parents.
+func (qr QuadTreeRemover[T]) collapse(node *Node[T]) {
+	items := make([]Item[T], 0, node.size)
+	dfs.DFSPostOrder(node, func(n *Node[T]) dfs.DFSControl {
+		items = append(items, n.items...)
+		if n != node {
+			n.items, n.childs, n.parent, n.summary = nil, nil, nil, nil
+		}
+		return dfs.Continue
 	})
-	return items
+	node.items, node.childs = items, nil
 }
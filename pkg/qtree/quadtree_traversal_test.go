@@ -0,0 +1,61 @@
+package qtree
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokg/pkg/plane"
+)
+
+func newTraversalTree() *QuadTree[float64] {
+	qtree := NewQuadTree(plane.NewEuclidean2D(1024.0, 1024.0))
+	addRandomBoxes(qtree, rand.New(rand.NewSource(46)), 5000)
+	return qtree
+}
+
+func TestQuadTree_WarmTraversalsDoNotAllocate(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector allocates")
+	}
+	qtree := newTraversalTree()
+	defer qtree.Close()
+	area := geom.NewAABBAt(geom.NewVec(100.0, 100.0), 300, 300)
+
+	for name, query := range map[string]func(){
+		"Count":       func() { qtree.Count() },
+		"Stats":       func() { qtree.Stats() },
+		"Depth":       func() { qtree.Depth() },
+		"CountInAABB": func() { qtree.CountInAABB(area) },
+	} {
+		query()
+		if allocs := testing.AllocsPerRun(100, query); allocs != 0 {
+			t.Errorf("%s allocated %v times per run, expected none", name, allocs)
+		}
+	}
+}
+
+func BenchmarkQuadTree_Traversals(b *testing.B) {
+	qtree := newTraversalTree()
+	defer qtree.Close()
+	area := geom.NewAABBAt(geom.NewVec(100.0, 100.0), 300, 300)
+
+	b.Run("Count", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			qtree.Count()
+		}
+	})
+	b.Run("Depth", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			qtree.Depth()
+		}
+	})
+	b.Run("CountInAABB", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			qtree.CountInAABB(area)
+		}
+	})
+}
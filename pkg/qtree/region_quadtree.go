@@ -0,0 +1,303 @@
+package qtree
+
+import (
+	"iter"
+	"math"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokg/pkg/plane"
+)
+
+// RegionQuadTree stores a value for every cell of a grid laid over the plane
+// viewport, such as walkability or fog-of-war maps. Square blocks of equal
+// cells are kept as a single node: four children holding the same value are
+// merged and a uniform node is split only when one of its cells changes. The
+// grid is padded to a power-of-two square; padding cells never block a merge.
+type RegionQuadTree[T geom.Numeric, V comparable] struct {
+	space    plane.Space2D[T]
+	viewport geom.AABB[T]
+	cellSize T
+	cols     int
+	rows     int
+	side     int
+	root     *regionNode[V]
+}
+
+type RegionQuadTreeOption[T geom.Numeric, V comparable] func(*RegionQuadTree[T, V])
+
+// WithRegionCellSize sets the edge length of a grid cell in plane units;
+// the default is 1.
+func WithRegionCellSize[T geom.Numeric, V comparable](size T) RegionQuadTreeOption[T, V] {
+	return func(t *RegionQuadTree[T, V]) {
+		if size > 0 {
+			t.cellSize = size
+		}
+	}
+}
+
+// regionNode is a uniform block when childs is nil, otherwise its quadrants
+// follow the order of geom.AABB.Split.
+type regionNode[V comparable] struct {
+	value  V
+	childs *[4]regionNode[V]
+}
+
+// cellRect is a half-open range of grid cells [x0, x1) x [y0, y1).
+type cellRect struct {
+	x0, y0, x1, y1 int
+}
+
+func (r cellRect) empty() bool { return r.x0 >= r.x1 || r.y0 >= r.y1 }
+
+func (r cellRect) contains(o cellRect) bool {
+	return r.x0 <= o.x0 && o.x1 <= r.x1 && r.y0 <= o.y0 && o.y1 <= r.y1
+}
+
+func (r cellRect) intersects(o cellRect) bool {
+	return r.x0 < o.x1 && o.x0 < r.x1 && r.y0 < o.y1 && o.y0 < r.y1
+}
+
+func (r cellRect) intersection(o cellRect) cellRect {
+	return cellRect{max(r.x0, o.x0), max(r.y0, o.y0), min(r.x1, o.x1), min(r.y1, o.y1)}
+}
+
+// NewRegionQuadTree builds a RegionQuadTree covering the supplied plane
+// viewport with every cell set to fill.
+func NewRegionQuadTree[T geom.Numeric, V comparable](
+	plane plane.Space2D[T],
+	fill V,
+	opts ...RegionQuadTreeOption[T, V],
+) *RegionQuadTree[T, V] {
+	t := &RegionQuadTree[T, V]{
+		space:    plane,
+		viewport: plane.Viewport(),
+		cellSize: 1,
+		root:     &regionNode[V]{value: fill},
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	cells := func(lo, hi T) int {
+		return max(int(math.Ceil((float64(hi)-float64(lo))/float64(t.cellSize))), 1)
+	}
+	t.cols = cells(t.viewport.TopLeft.X, t.viewport.BottomRight.X)
+	t.rows = cells(t.viewport.TopLeft.Y, t.viewport.BottomRight.Y)
+	t.side = 1
+	for t.side < max(t.cols, t.rows) {
+		t.side <<= 1
+	}
+	return t
+}
+
+// Set assigns v to the cell holding point (x, y). On cyclic planes the point
+// wraps around the edges; elsewhere points outside the viewport are rejected.
+func (t *RegionQuadTree[T, V]) Set(x, y T, v V) bool {
+	cx, cy, ok := t.cellOf(geom.NewVec(x, y))
+	if !ok {
+		return false
+	}
+	t.fill(t.root, cellRect{0, 0, t.side, t.side}, cellRect{cx, cy, cx + 1, cy + 1}, v)
+	return true
+}
+
+// Get returns the value of the cell holding point (x, y).
+func (t *RegionQuadTree[T, V]) Get(x, y T) (V, bool) {
+	cx, cy, ok := t.cellOf(geom.NewVec(x, y))
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	node, rect := t.root, cellRect{0, 0, t.side, t.side}
+	for node.childs != nil {
+		q, child := quadrantOf(rect, cx, cy)
+		node, rect = &node.childs[q], child
+	}
+	return node.value, true
+}
+
+// FillRect assigns v to every cell overlapping area. On cyclic planes the area
+// wraps around the edges.
+func (t *RegionQuadTree[T, V]) FillRect(area geom.AABB[T], v V) {
+	for _, rect := range t.cellRects(area) {
+		t.fill(t.root, cellRect{0, 0, t.side, t.side}, rect, v)
+	}
+}
+
+// AnyInAABB reports whether pred holds for any cell overlapping area. Uniform
+// blocks are tested once, so large empty or blocked regions are answered
+// without visiting their cells.
+func (t *RegionQuadTree[T, V]) AnyInAABB(area geom.AABB[T], pred func(V) bool) bool {
+	for _, rect := range t.cellRects(area) {
+		if t.any(t.root, cellRect{0, 0, t.side, t.side}, rect, pred) {
+			return true
+		}
+	}
+	return false
+}
+
+// Regions iterates over the uniform blocks of the tree, clipped to the
+// viewport, together with their values.
+func (t *RegionQuadTree[T, V]) Regions() iter.Seq2[geom.AABB[T], V] {
+	return func(yield func(geom.AABB[T], V) bool) {
+		t.regions(t.root, cellRect{0, 0, t.side, t.side}, yield)
+	}
+}
+
+// Close releases internal resources held by the tree.
+func (t *RegionQuadTree[T, V]) Close() {
+	t.root.childs = nil
+}
+
+func (t *RegionQuadTree[T, V]) fill(node *regionNode[V], rect, target cellRect, v V) {
+	if !rect.intersects(target) {
+		return
+	}
+	if target.contains(rect.intersection(t.grid())) {
+		node.value, node.childs = v, nil
+		return
+	}
+	if node.childs == nil {
+		if node.value == v {
+			return
+		}
+		node.childs = &[4]regionNode[V]{{value: node.value}, {value: node.value}, {value: node.value}, {value: node.value}}
+	}
+	for q := range node.childs {
+		t.fill(&node.childs[q], quadrantRect(rect, q), target, v)
+	}
+	t.merge(node, rect)
+}
+
+// merge collapses the node into a uniform block when its children overlapping
+// the grid are uniform blocks of the same value; children made of padding
+// cells only are ignored.
+func (t *RegionQuadTree[T, V]) merge(node *regionNode[V], rect cellRect) {
+	var value *V
+	for q := range node.childs {
+		child := &node.childs[q]
+		if !quadrantRect(rect, q).intersects(t.grid()) {
+			continue
+		}
+		if child.childs != nil || (value != nil && child.value != *value) {
+			return
+		}
+		value = &child.value
+	}
+	node.value, node.childs = *value, nil
+}
+
+// grid returns the range of real cells, without the power-of-two padding.
+func (t *RegionQuadTree[T, V]) grid() cellRect {
+	return cellRect{0, 0, t.cols, t.rows}
+}
+
+func (t *RegionQuadTree[T, V]) any(node *regionNode[V], rect, target cellRect, pred func(V) bool) bool {
+	if !rect.intersects(target) {
+		return false
+	}
+	if node.childs == nil {
+		return pred(node.value)
+	}
+	for q := range node.childs {
+		if t.any(&node.childs[q], quadrantRect(rect, q), target, pred) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *RegionQuadTree[T, V]) regions(node *regionNode[V], rect cellRect, yield func(geom.AABB[T], V) bool) bool {
+	if !rect.intersects(t.grid()) {
+		return true
+	}
+	if node.childs == nil {
+		return yield(t.boundsOf(rect), node.value)
+	}
+	for q := range node.childs {
+		if !t.regions(&node.childs[q], quadrantRect(rect, q), yield) {
+			return false
+		}
+	}
+	return true
+}
+
+// cellOf returns the grid cell holding vec.
+func (t *RegionQuadTree[T, V]) cellOf(vec geom.Vec[T]) (int, int, bool) {
+	if IsCyclic(t.space) {
+		vec = t.space.WrapVec(vec).TopLeft
+	}
+	if !t.viewport.IntersectsVec(vec) {
+		return 0, 0, false
+	}
+	cx := int((float64(vec.X) - float64(t.viewport.TopLeft.X)) / float64(t.cellSize))
+	cy := int((float64(vec.Y) - float64(t.viewport.TopLeft.Y)) / float64(t.cellSize))
+	return min(cx, t.cols-1), min(cy, t.rows-1), true
+}
+
+// cellRects converts area into the ranges of cells it overlaps: one range, or
+// one per fragment when the area wraps around the edges of a cyclic plane.
+func (t *RegionQuadTree[T, V]) cellRects(area geom.AABB[T]) []cellRect {
+	fragments := []geom.AABB[T]{area}
+	if IsCyclic(t.space) {
+		wrapped := t.space.WrapAABB(area)
+		fragments[0] = wrapped.AABB
+		wrapped.VisitFragments(func(_ plane.FragPosition, aabb geom.AABB[T]) bool {
+			fragments = append(fragments, aabb)
+			return true
+		})
+	}
+
+	rects := make([]cellRect, 0, len(fragments))
+	for _, fragment := range fragments {
+		x0, x1 := t.cellSpan(fragment.TopLeft.X, fragment.BottomRight.X, t.viewport.TopLeft.X, t.cols)
+		y0, y1 := t.cellSpan(fragment.TopLeft.Y, fragment.BottomRight.Y, t.viewport.TopLeft.Y, t.rows)
+		if rect := (cellRect{x0, y0, x1, y1}); !rect.empty() {
+			rects = append(rects, rect)
+		}
+	}
+	return rects
+}
+
+// cellSpan returns the cells [c0, c1) whose interior the interval [lo, hi]
+// overlaps; a degenerate interval selects the cell holding it.
+func (t *RegionQuadTree[T, V]) cellSpan(lo, hi, origin T, cells int) (int, int) {
+	size := float64(t.cellSize)
+	c0 := int(math.Floor((float64(lo) - float64(origin)) / size))
+	c1 := int(math.Ceil((float64(hi) - float64(origin)) / size))
+	if c1 <= c0 {
+		c1 = c0 + 1
+	}
+	return max(c0, 0), min(c1, cells)
+}
+
+func (t *RegionQuadTree[T, V]) boundsOf(rect cellRect) geom.AABB[T] {
+	origin := t.viewport.TopLeft
+	edge := func(o T, c int, limit T) T {
+		return min(T(float64(o)+float64(c)*float64(t.cellSize)), limit)
+	}
+	return geom.NewAABB(
+		geom.NewVec(edge(origin.X, rect.x0, t.viewport.BottomRight.X), edge(origin.Y, rect.y0, t.viewport.BottomRight.Y)),
+		geom.NewVec(edge(origin.X, rect.x1, t.viewport.BottomRight.X), edge(origin.Y, rect.y1, t.viewport.BottomRight.Y)),
+	)
+}
+
+// quadrantRect returns quadrant q of a square cell range, in the order of
+// geom.AABB.Split.
+func quadrantRect(rect cellRect, q int) cellRect {
+	half := (rect.x1 - rect.x0) / 2
+	x0 := rect.x0 + (q&1)*half
+	y0 := rect.y0 + (q>>1)*half
+	return cellRect{x0, y0, x0 + half, y0 + half}
+}
+
+func quadrantOf(rect cellRect, cx, cy int) (int, cellRect) {
+	half := (rect.x1 - rect.x0) / 2
+	q := 0
+	if cx >= rect.x0+half {
+		q |= 1
+	}
+	if cy >= rect.y0+half {
+		q |= 2
+	}
+	return q, quadrantRect(rect, q)
+}
@@ -0,0 +1,163 @@
+package qtree
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokg/pkg/plane"
+)
+
+func TestRegionQuadTree_MatchesNaiveGrid(t *testing.T) {
+	for _, space := range []plane.Space2D[int]{
+		plane.NewEuclidean2D(40, 24),
+		plane.NewToroidal2D(40, 24),
+	} {
+		t.Run(space.Name(), func(t *testing.T) {
+			tree := NewRegionQuadTree(space, 0)
+			defer tree.Close()
+			grid := [24][40]int{}
+
+			rnd := rand.New(rand.NewSource(34))
+			for i := range 300 {
+				v := rnd.Intn(3)
+				if i%3 == 0 {
+					x, y := rnd.Intn(40), rnd.Intn(24)
+					tree.Set(x, y, v)
+					grid[y][x] = v
+					continue
+				}
+				x, y, w, h := rnd.Intn(44)-2, rnd.Intn(28)-2, rnd.Intn(12), rnd.Intn(12)
+				tree.FillRect(geom.NewAABBAt(geom.NewVec(x, y), w, h), v)
+				naiveFill(&grid, space, x, y, w, h, v)
+			}
+
+			for y := range 24 {
+				for x := range 40 {
+					if got, ok := tree.Get(x, y); !ok || got != grid[y][x] {
+						t.Fatalf("Get(%d, %d) = %d, expected %d", x, y, got, grid[y][x])
+					}
+				}
+			}
+			cells := 0
+			for region, v := range tree.Regions() {
+				for y := region.TopLeft.Y; y < region.BottomRight.Y; y++ {
+					for x := region.TopLeft.X; x < region.BottomRight.X; x++ {
+						if grid[y][x] != v {
+							t.Fatalf("region %v holds %d but cell (%d,%d) is %d", region, v, x, y, grid[y][x])
+						}
+						cells++
+					}
+				}
+			}
+			if cells != 40*24 {
+				t.Errorf("regions cover %d cells, expected %d", cells, 40*24)
+			}
+
+			for range 200 {
+				x, y, w, h := rnd.Intn(40), rnd.Intn(24), rnd.Intn(8), rnd.Intn(8)
+				expected := false
+				naiveVisit(space, x, y, w, h, func(cx, cy int) { expected = expected || grid[cy][cx] == 2 })
+				area := geom.NewAABBAt(geom.NewVec(x, y), w, h)
+				if got := tree.AnyInAABB(area, func(v int) bool { return v == 2 }); got != expected {
+					t.Fatalf("AnyInAABB(%v) = %v, expected %v", area, got, expected)
+				}
+			}
+		})
+	}
+}
+
+// naiveVisit calls fn for the cells overlapped by the w x h box at (x, y),
+// wrapping on toroidal planes and clipping on Euclidean ones.
+func naiveVisit(space plane.Space2D[int], x, y, w, h int, fn func(cx, cy int)) {
+	toroidal := IsCyclic(space)
+	for cy := y; cy < y+max(h, 1); cy++ {
+		for cx := x; cx < x+max(w, 1); cx++ {
+			px, py := cx, cy
+			if toroidal {
+				px, py = (cx%40+40)%40, (cy%24+24)%24
+			} else if cx < 0 || cy < 0 || cx >= 40 || cy >= 24 {
+				continue
+			}
+			fn(px, py)
+		}
+	}
+}
+
+func naiveFill(grid *[24][40]int, space plane.Space2D[int], x, y, w, h, v int) {
+	naiveVisit(space, x, y, w, h, func(cx, cy int) { grid[cy][cx] = v })
+}
+
+func TestRegionQuadTree_MergesUniformBlocks(t *testing.T) {
+	tree := NewRegionQuadTree(plane.NewEuclidean2D(64.0, 64.0), false, WithRegionCellSize[float64, bool](4))
+	defer tree.Close()
+
+	tree.Set(10, 10, true)
+	if regions := countRegions(tree); regions != 13 {
+		t.Errorf("expected a single set cell to split 4 levels into 13 regions, got %d", regions)
+	}
+	tree.FillRect(geom.NewAABBAt(geom.NewVec(8.0, 8.0), 8, 8), true)
+	if regions := countRegions(tree); regions != 10 {
+		t.Errorf("expected the filled 2x2 block to merge into 10 regions, got %d", regions)
+	}
+	tree.FillRect(geom.NewAABBAt(geom.NewVec(0.0, 0.0), 64, 64), false)
+	if regions := countRegions(tree); regions != 1 {
+		t.Errorf("expected clearing the plane to merge back to the root, got %d regions", regions)
+	}
+
+	if tree.Set(70, 10, true) {
+		t.Errorf("expected a point outside the viewport to be rejected")
+	}
+	if _, ok := tree.Get(-1, 0); ok {
+		t.Errorf("expected Get outside the viewport to fail")
+	}
+}
+
+func TestRegionQuadTree_PaddingDoesNotBlockMerge(t *testing.T) {
+	tree := NewRegionQuadTree(plane.NewEuclidean2D(40, 24), 0)
+	defer tree.Close()
+
+	tree.FillRect(geom.NewAABBAt(geom.NewVec(0, 0), 40, 24), 1)
+	if tree.root.childs != nil || countRegions(tree) != 1 {
+		t.Errorf("expected filling the viewport to merge into the root, got %d regions", countRegions(tree))
+	}
+
+	tree.Set(5, 5, 2)
+	for y := range 24 {
+		for x := range 40 {
+			tree.Set(x, y, 3)
+		}
+	}
+	if tree.root.childs != nil {
+		t.Errorf("expected setting every cell to merge into the root, got %d regions", countRegions(tree))
+	}
+	for bounds, v := range tree.Regions() {
+		if v != 3 || bounds != tree.viewport {
+			t.Errorf("Regions() = %v: %d, expected the viewport set to 3", bounds, v)
+		}
+	}
+}
+
+func countRegions[T geom.Numeric, V comparable](tree *RegionQuadTree[T, V]) int {
+	count := 0
+	for range tree.Regions() {
+		count++
+	}
+	return count
+}
+
+func ExampleRegionQuadTree_AnyInAABB() {
+	walls := NewRegionQuadTree(plane.NewToroidal2D(16, 16), false)
+	defer walls.Close()
+
+	walls.FillRect(geom.NewAABBAt(geom.NewVec(14, 4), 4, 1), true)
+
+	blocked := func(wall bool) bool { return wall }
+	fmt.Println(walls.AnyInAABB(geom.NewAABBAt(geom.NewVec(0, 3), 2, 2), blocked))
+	fmt.Println(walls.AnyInAABB(geom.NewAABBAt(geom.NewVec(4, 3), 2, 2), blocked))
+
+	// Output:
+	// true
+	// false
+}
@@ -0,0 +1,36 @@
+package qtree
+
+import (
+	"math"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokg/pkg/plane"
+)
+
+// IsCyclic reports whether space wraps around the edges of its viewport, as
+// the toroidal plane does.
+func IsCyclic[T geom.Numeric](space plane.Space2D[T]) bool {
+	return space.Name() == "Toroidal2D"
+}
+
+// PointDistance returns the distance from pos to the nearest point of box. On
+// cyclic planes it is taken the shorter way around the edges. A box never lies
+// closer to pos than a box containing it, which lets kNN queries rank tree
+// nodes by their bounds.
+func PointDistance[T geom.Numeric](space plane.Space2D[T], pos geom.Vec[T], box geom.AABB[T]) float64 {
+	cyclic := IsCyclic(space)
+	viewport := space.Viewport()
+	axis := func(v, lo, hi, vlo, vhi T) float64 {
+		gap := func(v float64) float64 { return max(float64(lo)-v, v-float64(hi), 0) }
+		d := gap(float64(v))
+		if cyclic && d > 0 {
+			size := float64(vhi) - float64(vlo)
+			d = min(d, gap(float64(v)-size), gap(float64(v)+size))
+		}
+		return d
+	}
+	return math.Hypot(
+		axis(pos.X, box.TopLeft.X, box.BottomRight.X, viewport.TopLeft.X, viewport.BottomRight.X),
+		axis(pos.Y, box.TopLeft.Y, box.BottomRight.Y, viewport.TopLeft.Y, viewport.BottomRight.Y),
+	)
+}
@@ -0,0 +1,33 @@
+package qtree
+
+import (
+	"github.com/kjkrol/gokg/pkg/geom"
+)
+
+// SpatialIndex is the common surface of the spatial indexes in this module.
+// Code that depends on it rather than on *QuadTree can switch to another
+// backend, or to a wrapper adding locking, metrics or logging, without
+// changes. The qtreetest package checks an implementation against the
+// expected semantics.
+type SpatialIndex[T geom.Numeric] interface {
+	// Add inserts item; returns false if it cannot be placed.
+	Add(item Item[T]) bool
+	// Remove deletes item, matched by identity; returns false when nothing was removed.
+	Remove(item Item[T]) bool
+	// FindNeighbors retrieves items within margin of the target's bounds,
+	// excluding the target itself, ordered as SortItems.
+	FindNeighbors(target Item[T], margin T) []Item[T]
+	// BatchUpdate removes a batch of items and inserts the replacements.
+	BatchUpdate(toRemove []Item[T], toAdd []Item[T], triggerCompression bool)
+	// Count returns the number of stored items.
+	Count() int
+	// AllItems returns a snapshot of every stored item.
+	AllItems() []Item[T]
+	// Close releases internal resources held by the index.
+	Close()
+}
+
+var (
+	_ SpatialIndex[float64] = (*QuadTree[float64])(nil)
+	_ SpatialIndex[float64] = (*LinearQuadTree[float64])(nil)
+)
@@ -0,0 +1,27 @@
+package qtree_test
+
+import (
+	"testing"
+
+	"github.com/kjkrol/gokg/pkg/plane"
+	"github.com/kjkrol/gokq/pkg/qtree"
+	"github.com/kjkrol/gokq/pkg/qtree/qtreetest"
+)
+
+func TestQuadTree_Conformance(t *testing.T) {
+	qtreetest.Run(t, func(space plane.Space2D[float64]) qtree.SpatialIndex[float64] {
+		return qtree.NewQuadTree(space)
+	})
+}
+
+func TestQuadTree_Loose_Conformance(t *testing.T) {
+	qtreetest.Run(t, func(space plane.Space2D[float64]) qtree.SpatialIndex[float64] {
+		return qtree.NewQuadTree(space, qtree.WithLooseness[float64](2))
+	})
+}
+
+func TestLinearQuadTree_Conformance(t *testing.T) {
+	qtreetest.Run(t, func(space plane.Space2D[float64]) qtree.SpatialIndex[float64] {
+		return qtree.NewLinearQuadTree(space)
+	})
+}
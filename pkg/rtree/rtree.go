@@ -0,0 +1,234 @@
+// Package rtree provides an R*-tree, a spatial index suited to many heavily
+// overlapping rectangles of widely differing sizes, where a quadtree keeps
+// most of them near the root. It stores qtree.Item values and implements
+// qtree.SpatialIndex.
+package rtree
+
+import (
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokg/pkg/plane"
+	"github.com/kjkrol/gokq/pkg/dfs"
+	"github.com/kjkrol/gokq/pkg/qtree"
+)
+
+const (
+	MAX_ENTRIES int = 16
+	// REINSERT_FACTOR is the share of entries an overflowing node hands back
+	// for reinsertion before it is split, as in the R*-tree paper.
+	REINSERT_FACTOR float64 = 0.3
+	// MIN_FILL_FACTOR is the share of MAX_ENTRIES below which a node underflows.
+	MIN_FILL_FACTOR float64 = 0.4
+)
+
+// RTree is an R*-tree covering a plane viewport.
+type RTree[T geom.Numeric] struct {
+	space      plane.Space2D[T]
+	viewport   geom.AABB[T]
+	maxEntries int
+	minEntries int
+	root       *node[T]
+	count      int
+}
+
+var _ qtree.SpatialIndex[float64] = (*RTree[float64])(nil)
+
+type RTreeOption[T geom.Numeric] func(*RTree[T])
+
+// WithMaxEntries sets the node capacity; the minimum fill follows as 40% of it.
+func WithMaxEntries[T geom.Numeric](maxEntries int) RTreeOption[T] {
+	return func(t *RTree[T]) {
+		if maxEntries >= 4 {
+			t.maxEntries = maxEntries
+		}
+	}
+}
+
+// node is a leaf holding items at level 0, or an internal node holding child
+// nodes one level below it. bounds encloses all its entries.
+type node[T geom.Numeric] struct {
+	bounds geom.AABB[T]
+	level  int
+	items  []qtree.Item[T]
+	childs []*node[T]
+	parent *node[T]
+}
+
+func (n *node[T]) Children() []*node[T] { return n.childs }
+
+func (n *node[T]) isLeaf() bool { return n.level == 0 }
+
+func (n *node[T]) entries() int {
+	if n.isLeaf() {
+		return len(n.items)
+	}
+	return len(n.childs)
+}
+
+// recomputeBounds sets bounds to the union of the node's entries.
+func (n *node[T]) recomputeBounds() {
+	if n.isLeaf() {
+		n.bounds = unionOf(n.items, qtree.Item[T].Bound)
+		return
+	}
+	n.bounds = unionOf(n.childs, (*node[T]).boundsOf)
+}
+
+func (n *node[T]) boundsOf() geom.AABB[T] { return n.bounds }
+
+// NewRTree builds an RTree covering the supplied plane viewport.
+func NewRTree[T geom.Numeric](
+	plane plane.Space2D[T],
+	opts ...RTreeOption[T],
+) *RTree[T] {
+	t := &RTree[T]{
+		space:      plane,
+		viewport:   plane.Viewport(),
+		maxEntries: MAX_ENTRIES,
+		root:       &node[T]{},
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	t.minEntries = max(int(float64(t.maxEntries)*MIN_FILL_FACTOR), 2)
+	return t
+}
+
+// Add inserts item into the tree; returns false if it lies outside the viewport.
+func (t *RTree[T]) Add(item qtree.Item[T]) bool {
+	if !t.viewport.Contains(item.Bound()) {
+		return false
+	}
+	t.insertItem(item, make(map[int]bool))
+	t.count++
+	return true
+}
+
+// Remove deletes item from the tree; returns false when nothing was removed.
+func (t *RTree[T]) Remove(item qtree.Item[T]) bool {
+	leaf, i := t.findLeaf(item)
+	if leaf == nil {
+		return false
+	}
+	leaf.items = append(leaf.items[:i], leaf.items[i+1:]...)
+	t.count--
+	t.condense(leaf)
+	return true
+}
+
+// Close releases internal resources held by the tree.
+func (t *RTree[T]) Close() {
+	t.root = &node[T]{}
+	t.count = 0
+}
+
+// Count returns the number of items stored in the tree.
+func (t *RTree[T]) Count() int {
+	return t.count
+}
+
+// Depth reports the number of levels of the tree.
+func (t *RTree[T]) Depth() int {
+	return t.root.level + 1
+}
+
+// AllItems returns a snapshot of every stored item, ordered as qtree.SortItems.
+func (t *RTree[T]) AllItems() []qtree.Item[T] {
+	items := make([]qtree.Item[T], 0, t.count)
+	dfs.DFS(t.root, struct{}{}, func(n *node[T], _ struct{}) (dfs.DFSControl, struct{}) {
+		items = append(items, n.items...)
+		return dfs.Continue, struct{}{}
+	})
+	qtree.SortItems(items)
+	return items
+}
+
+// BatchUpdate removes a batch of items, matched by identity, and inserts the
+// replacements. Each item is looked up by its current bounds first; only the
+// items not found there, e.g. whose bounds changed since insertion, are removed
+// by scanning every leaf. Setting triggerCompression repacks the whole tree
+// with STR bulk loading afterwards.
+func (t *RTree[T]) BatchUpdate(toRemove []qtree.Item[T], toAdd []qtree.Item[T], triggerCompression bool) {
+	if t == nil || t.root == nil {
+		return
+	}
+
+	if len(toRemove) > 0 {
+		touched := []*node[T]{}
+		seen := map[*node[T]]struct{}{}
+		touch := func(leaf *node[T]) {
+			if _, ok := seen[leaf]; !ok {
+				seen[leaf] = struct{}{}
+				touched = append(touched, leaf)
+			}
+		}
+
+		pending := []qtree.Item[T]{}
+		for _, item := range toRemove {
+			leaf, i := t.findLeaf(item)
+			if leaf == nil {
+				pending = append(pending, item)
+				continue
+			}
+			leaf.items = append(leaf.items[:i], leaf.items[i+1:]...)
+			t.count--
+			touch(leaf)
+		}
+
+		if len(pending) > 0 {
+			dfs.DFS(t.root, struct{}{}, func(n *node[T], _ struct{}) (dfs.DFSControl, struct{}) {
+				if len(pending) == 0 {
+					return dfs.Stop, struct{}{}
+				}
+				before := len(n.items)
+				n.items = removeMatching(n.items, &pending)
+				if removed := before - len(n.items); removed > 0 {
+					t.count -= removed
+					touch(n)
+				}
+				return dfs.Continue, struct{}{}
+			})
+		}
+		if !triggerCompression {
+			for _, leaf := range touched {
+				if t.attached(leaf) {
+					t.condense(leaf)
+				}
+			}
+		}
+	}
+
+	if triggerCompression {
+		items := t.AllItems()
+		t.root, t.count = &node[T]{}, 0
+		t.BulkLoad(append(items, toAdd...))
+		return
+	}
+	for _, item := range toAdd {
+		t.Add(item)
+	}
+}
+
+// attached reports whether n is still reachable from the root.
+func (t *RTree[T]) attached(n *node[T]) bool {
+	for ; n.parent != nil; n = n.parent {
+	}
+	return n == t.root
+}
+
+func removeMatching[T geom.Numeric](items []qtree.Item[T], pending *[]qtree.Item[T]) []qtree.Item[T] {
+	keep := items[:0]
+	for _, item := range items {
+		matched := false
+		for i, target := range *pending {
+			if qtree.SameItem(item, target) {
+				*pending = append((*pending)[:i], (*pending)[i+1:]...)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			keep = append(keep, item)
+		}
+	}
+	return keep
+}
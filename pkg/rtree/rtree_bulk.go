@@ -0,0 +1,77 @@
+package rtree
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokq/pkg/qtree"
+)
+
+// BulkLoad adds items and repacks the whole tree with Sort-Tile-Recursive
+// packing, which yields fuller nodes with less overlap than one-by-one
+// insertion. Items outside the viewport are skipped; the number of items added
+// is returned.
+func (t *RTree[T]) BulkLoad(items []qtree.Item[T]) int {
+	all := t.AllItems()
+	added := 0
+	for _, item := range items {
+		if t.viewport.Contains(item.Bound()) {
+			all = append(all, item)
+			added++
+		}
+	}
+	t.root = t.pack(all)
+	t.count = len(all)
+	return added
+}
+
+// pack builds a tree over items bottom-up: entries are tiled into vertical
+// slices by centre X, each slice is cut into nodes by centre Y, and the
+// resulting nodes are packed the same way until a single root remains.
+func (t *RTree[T]) pack(items []qtree.Item[T]) *node[T] {
+	if len(items) == 0 {
+		return &node[T]{}
+	}
+
+	var level []*node[T]
+	for _, group := range strTiles(items, qtree.Item[T].Bound, t.maxEntries) {
+		leaf := &node[T]{items: group}
+		leaf.recomputeBounds()
+		level = append(level, leaf)
+	}
+	for height := 1; len(level) > 1; height++ {
+		var parents []*node[T]
+		for _, group := range strTiles(level, (*node[T]).boundsOf, t.maxEntries) {
+			parent := &node[T]{level: height, childs: group}
+			for _, child := range group {
+				child.parent = parent
+			}
+			parent.recomputeBounds()
+			parents = append(parents, parent)
+		}
+		level = parents
+	}
+	return level[0]
+}
+
+func strTiles[E any, T geom.Numeric](entries []E, boundOf func(E) geom.AABB[T], capacity int) [][]E {
+	sorted := slices.Clone(entries)
+	centerX := func(e E) float64 { x, _ := center(boundOf(e)); return x }
+	centerY := func(e E) float64 { _, y := center(boundOf(e)); return y }
+	slices.SortStableFunc(sorted, func(a, b E) int { return cmp.Compare(centerX(a), centerX(b)) })
+
+	nodes := int(math.Ceil(float64(len(sorted)) / float64(capacity)))
+	sliceSize := int(math.Ceil(math.Sqrt(float64(nodes)))) * capacity
+
+	groups := make([][]E, 0, nodes)
+	for start := 0; start < len(sorted); start += sliceSize {
+		column := sorted[start:min(start+sliceSize, len(sorted))]
+		slices.SortStableFunc(column, func(a, b E) int { return cmp.Compare(centerY(a), centerY(b)) })
+		for i := 0; i < len(column); i += capacity {
+			groups = append(groups, slices.Clone(column[i:min(i+capacity, len(column))]))
+		}
+	}
+	return groups
+}
@@ -0,0 +1,151 @@
+package rtree
+
+import (
+	"container/heap"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokg/pkg/plane"
+	"github.com/kjkrol/gokq/pkg/dfs"
+	"github.com/kjkrol/gokq/pkg/qtree"
+)
+
+// FindNeighbors retrieves items within margin of the target's bounds. The
+// probe is wrapped and fragmented by the plane exactly as
+// qtree.DefaultQuadTreeFinderStrategy does, so results match QuadTree on
+// cyclic planes too.
+func (t *RTree[T]) FindNeighbors(target qtree.Item[T], margin T) []qtree.Item[T] {
+	probe := t.space.WrapAABB(target.Bound())
+	t.space.Expand(&probe, margin)
+	fragments := fragmentsOf(probe)
+
+	boundingBoxDistance := t.space.AABBDistance()
+	neighbors := make([]qtree.Item[T], 0)
+	t.search(fragments, func(item qtree.Item[T]) {
+		if item.SameID(target) {
+			return
+		}
+		if boundingBoxDistance(target.Bound(), item.Bound()) <= margin {
+			neighbors = append(neighbors, item)
+		}
+	})
+
+	qtree.SortItems(neighbors)
+	return neighbors
+}
+
+// FindInAABB returns the items intersecting area, ordered as qtree.SortItems.
+// On cyclic planes the area wraps around the edges.
+func (t *RTree[T]) FindInAABB(area geom.AABB[T]) []qtree.Item[T] {
+	fragments := fragmentsOf(t.space.WrapAABB(area))
+	if !qtree.IsCyclic(t.space) {
+		fragments = []geom.AABB[T]{area}
+	}
+
+	found := make([]qtree.Item[T], 0)
+	t.search(fragments, func(item qtree.Item[T]) {
+		for _, fragment := range fragments {
+			if fragment.Intersects(item.Bound()) {
+				found = append(found, item)
+				return
+			}
+		}
+	})
+
+	qtree.SortItems(found)
+	return found
+}
+
+// Nearest returns up to k items closest to pos, nearest first. Distances are
+// measured from pos to the nearest point of each item's bounds; on cyclic
+// planes they are taken the shorter way around the edges.
+func (t *RTree[T]) Nearest(pos geom.Vec[T], k int) []qtree.Item[T] {
+	nearest := make([]qtree.Item[T], 0, max(k, 0))
+	if k <= 0 || t.count == 0 {
+		return nearest
+	}
+
+	queue := &nearestQueue[T]{{node: t.root, dist: t.pointDistance(pos, t.root.bounds)}}
+	for queue.Len() > 0 && len(nearest) < k {
+		next := heap.Pop(queue).(nearestEntry[T])
+		switch {
+		case next.item != nil:
+			nearest = append(nearest, next.item)
+		case next.node.isLeaf():
+			for _, item := range next.node.items {
+				heap.Push(queue, nearestEntry[T]{item: item, dist: t.pointDistance(pos, item.Bound())})
+			}
+		default:
+			for _, child := range next.node.childs {
+				heap.Push(queue, nearestEntry[T]{node: child, dist: t.pointDistance(pos, child.bounds)})
+			}
+		}
+	}
+	return nearest
+}
+
+// search calls fn for the items of every leaf whose bounds intersect any of
+// the fragments; fn decides whether an item matches.
+func (t *RTree[T]) search(fragments []geom.AABB[T], fn func(qtree.Item[T])) {
+	if t.count == 0 {
+		return
+	}
+	dfs.DFS(t.root, struct{}{}, func(n *node[T], _ struct{}) (dfs.DFSControl, struct{}) {
+		if !intersectsAny(n.bounds, fragments) {
+			return dfs.SkipChildren, struct{}{}
+		}
+		for _, item := range n.items {
+			fn(item)
+		}
+		return dfs.Continue, struct{}{}
+	})
+}
+
+// pointDistance returns the distance from pos to the nearest point of box.
+func (t *RTree[T]) pointDistance(pos geom.Vec[T], box geom.AABB[T]) float64 {
+	return qtree.PointDistance(t.space, pos, box)
+}
+
+func fragmentsOf[T geom.Numeric](probe plane.AABB[T]) []geom.AABB[T] {
+	fragments := []geom.AABB[T]{probe.AABB}
+	probe.VisitFragments(func(_ plane.FragPosition, aabb geom.AABB[T]) bool {
+		fragments = append(fragments, aabb)
+		return true
+	})
+	return fragments
+}
+
+func intersectsAny[T geom.Numeric](box geom.AABB[T], fragments []geom.AABB[T]) bool {
+	for _, fragment := range fragments {
+		if box.Intersects(fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// nearestEntry is a node or an item queued by Nearest.
+type nearestEntry[T geom.Numeric] struct {
+	node *node[T]
+	item qtree.Item[T]
+	dist float64
+}
+
+// nearestQueue is a min-heap of entries ordered by distance; items are popped
+// before nodes at the same distance.
+type nearestQueue[T geom.Numeric] []nearestEntry[T]
+
+func (q nearestQueue[T]) Len() int { return len(q) }
+func (q nearestQueue[T]) Less(i, j int) bool {
+	if q[i].dist != q[j].dist {
+		return q[i].dist < q[j].dist
+	}
+	return q[i].item != nil && q[j].item == nil
+}
+func (q nearestQueue[T]) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *nearestQueue[T]) Push(x any)   { *q = append(*q, x.(nearestEntry[T])) }
+func (q *nearestQueue[T]) Pop() any {
+	old := *q
+	last := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return last
+}
@@ -0,0 +1,46 @@
+package rtree
+
+import (
+	"github.com/kjkrol/gokg/pkg/geom"
+)
+
+func union[T geom.Numeric](a, b geom.AABB[T]) geom.AABB[T] {
+	return geom.NewAABB(
+		geom.NewVec(min(a.TopLeft.X, b.TopLeft.X), min(a.TopLeft.Y, b.TopLeft.Y)),
+		geom.NewVec(max(a.BottomRight.X, b.BottomRight.X), max(a.BottomRight.Y, b.BottomRight.Y)),
+	)
+}
+
+func unionOf[E any, T geom.Numeric](entries []E, boundOf func(E) geom.AABB[T]) geom.AABB[T] {
+	if len(entries) == 0 {
+		return geom.AABB[T]{}
+	}
+	box := boundOf(entries[0])
+	for _, e := range entries[1:] {
+		box = union(box, boundOf(e))
+	}
+	return box
+}
+
+func area[T geom.Numeric](box geom.AABB[T]) float64 {
+	return (float64(box.BottomRight.X) - float64(box.TopLeft.X)) * (float64(box.BottomRight.Y) - float64(box.TopLeft.Y))
+}
+
+// margin returns half the perimeter of the box.
+func margin[T geom.Numeric](box geom.AABB[T]) float64 {
+	return (float64(box.BottomRight.X) - float64(box.TopLeft.X)) + (float64(box.BottomRight.Y) - float64(box.TopLeft.Y))
+}
+
+func overlap[T geom.Numeric](a, b geom.AABB[T]) float64 {
+	w := min(float64(a.BottomRight.X), float64(b.BottomRight.X)) - max(float64(a.TopLeft.X), float64(b.TopLeft.X))
+	h := min(float64(a.BottomRight.Y), float64(b.BottomRight.Y)) - max(float64(a.TopLeft.Y), float64(b.TopLeft.Y))
+	if w <= 0 || h <= 0 {
+		return 0
+	}
+	return w * h
+}
+
+func center[T geom.Numeric](box geom.AABB[T]) (float64, float64) {
+	return (float64(box.TopLeft.X) + float64(box.BottomRight.X)) / 2,
+		(float64(box.TopLeft.Y) + float64(box.BottomRight.Y)) / 2
+}
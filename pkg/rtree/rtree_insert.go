@@ -0,0 +1,195 @@
+package rtree
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokq/pkg/qtree"
+)
+
+// insertItem places item in the leaf chosen by chooseSubtree. reinserted
+// records the levels that already handed entries back during the current
+// insertion, so each level does so at most once.
+func (t *RTree[T]) insertItem(item qtree.Item[T], reinserted map[int]bool) {
+	leaf := t.chooseSubtree(item.Bound(), 0)
+	leaf.items = append(leaf.items, item)
+	t.handleOverflow(leaf, reinserted)
+}
+
+// insertNode attaches the subtree child to a node one level above it.
+func (t *RTree[T]) insertNode(child *node[T], reinserted map[int]bool) {
+	target := t.chooseSubtree(child.bounds, child.level+1)
+	child.parent = target
+	target.childs = append(target.childs, child)
+	t.handleOverflow(target, reinserted)
+}
+
+// chooseSubtree descends from the root to the node at level that needs the
+// least enlargement to hold box. Above the leaves the R* criterion of least
+// overlap enlargement is used.
+func (t *RTree[T]) chooseSubtree(box geom.AABB[T], level int) *node[T] {
+	n := t.root
+	for n.level > level {
+		best, bestOverlap, bestGrowth, bestArea := n.childs[0], math.Inf(1), math.Inf(1), math.Inf(1)
+		for _, child := range n.childs {
+			grown := union(child.bounds, box)
+			growth := area(grown) - area(child.bounds)
+			overlapGrowth := 0.0
+			if n.level == 1 {
+				for _, other := range n.childs {
+					if other != child {
+						overlapGrowth += overlap(grown, other.bounds) - overlap(child.bounds, other.bounds)
+					}
+				}
+			}
+			childArea := area(child.bounds)
+			if cmp.Or(
+				cmp.Compare(overlapGrowth, bestOverlap),
+				cmp.Compare(growth, bestGrowth),
+				cmp.Compare(childArea, bestArea),
+			) < 0 {
+				best, bestOverlap, bestGrowth, bestArea = child, overlapGrowth, growth, childArea
+			}
+		}
+		n = best
+	}
+	return n
+}
+
+// handleOverflow restores the capacity invariant from n upwards: an
+// overflowing node first hands entries back for reinsertion and is split when
+// that was already done on its level; bounds are refreshed up to the root.
+func (t *RTree[T]) handleOverflow(n *node[T], reinserted map[int]bool) {
+	for n != nil {
+		if n.entries() <= t.maxEntries {
+			t.adjustBounds(n)
+			return
+		}
+		if n != t.root && !reinserted[n.level] {
+			reinserted[n.level] = true
+			t.reinsert(n, reinserted)
+			return
+		}
+
+		sibling := t.split(n)
+		if n == t.root {
+			t.root = &node[T]{level: n.level + 1, childs: []*node[T]{n, sibling}}
+			n.parent, sibling.parent = t.root, t.root
+			t.root.recomputeBounds()
+			return
+		}
+		sibling.parent = n.parent
+		n.parent.childs = append(n.parent.childs, sibling)
+		n = n.parent
+	}
+}
+
+func (t *RTree[T]) adjustBounds(n *node[T]) {
+	for ; n != nil; n = n.parent {
+		n.recomputeBounds()
+	}
+}
+
+// reinsert removes the entries farthest from the node centre and inserts them
+// again, closest first, which often finds them a better place than a split.
+func (t *RTree[T]) reinsert(n *node[T], reinserted map[int]bool) {
+	count := max(int(float64(t.maxEntries)*REINSERT_FACTOR), 1)
+	cx, cy := center(n.bounds)
+	distance := func(box geom.AABB[T]) float64 {
+		x, y := center(box)
+		return (x-cx)*(x-cx) + (y-cy)*(y-cy)
+	}
+
+	if n.isLeaf() {
+		slices.SortStableFunc(n.items, func(a, b qtree.Item[T]) int {
+			return cmp.Compare(distance(b.Bound()), distance(a.Bound()))
+		})
+		removed := slices.Clone(n.items[:count])
+		n.items = slices.Delete(n.items, 0, count)
+		t.adjustBounds(n)
+		for i := len(removed) - 1; i >= 0; i-- {
+			t.insertItem(removed[i], reinserted)
+		}
+		return
+	}
+
+	slices.SortStableFunc(n.childs, func(a, b *node[T]) int {
+		return cmp.Compare(distance(b.bounds), distance(a.bounds))
+	})
+	removed := slices.Clone(n.childs[:count])
+	n.childs = slices.Delete(n.childs, 0, count)
+	t.adjustBounds(n)
+	for i := len(removed) - 1; i >= 0; i-- {
+		t.insertNode(removed[i], reinserted)
+	}
+}
+
+// split moves part of n's entries into a new sibling using the R* split.
+func (t *RTree[T]) split(n *node[T]) *node[T] {
+	sibling := &node[T]{level: n.level}
+	if n.isLeaf() {
+		n.items, sibling.items = splitEntries(n.items, qtree.Item[T].Bound, t.minEntries)
+	} else {
+		n.childs, sibling.childs = splitEntries(n.childs, (*node[T]).boundsOf, t.minEntries)
+		for _, child := range sibling.childs {
+			child.parent = sibling
+		}
+	}
+	n.recomputeBounds()
+	sibling.recomputeBounds()
+	return sibling
+}
+
+// splitEntries picks the split axis with the least total margin over all
+// distributions, then the distribution on that axis with the least overlap,
+// breaking ties by total area.
+func splitEntries[E any, T geom.Numeric](
+	entries []E,
+	boundOf func(E) geom.AABB[T],
+	minEntries int,
+) ([]E, []E) {
+	sortings := [][]func(E) float64{
+		{
+			func(e E) float64 { return float64(boundOf(e).TopLeft.X) },
+			func(e E) float64 { return float64(boundOf(e).BottomRight.X) },
+		},
+		{
+			func(e E) float64 { return float64(boundOf(e).TopLeft.Y) },
+			func(e E) float64 { return float64(boundOf(e).BottomRight.Y) },
+		},
+	}
+
+	var bestAxis [][]E
+	bestMargin := math.Inf(1)
+	for _, axis := range sortings {
+		sorted := make([][]E, 0, len(axis))
+		total := 0.0
+		for _, key := range axis {
+			candidate := slices.Clone(entries)
+			slices.SortStableFunc(candidate, func(a, b E) int { return cmp.Compare(key(a), key(b)) })
+			for k := minEntries; k <= len(candidate)-minEntries; k++ {
+				total += margin(unionOf(candidate[:k], boundOf)) + margin(unionOf(candidate[k:], boundOf))
+			}
+			sorted = append(sorted, candidate)
+		}
+		if total < bestMargin {
+			bestMargin, bestAxis = total, sorted
+		}
+	}
+
+	var left, right []E
+	bestOverlap, bestArea := math.Inf(1), math.Inf(1)
+	for _, candidate := range bestAxis {
+		for k := minEntries; k <= len(candidate)-minEntries; k++ {
+			a, b := unionOf(candidate[:k], boundOf), unionOf(candidate[k:], boundOf)
+			o, s := overlap(a, b), area(a)+area(b)
+			if o < bestOverlap || (o == bestOverlap && s < bestArea) {
+				bestOverlap, bestArea = o, s
+				left, right = candidate[:k], candidate[k:]
+			}
+		}
+	}
+	return slices.Clone(left), slices.Clone(right)
+}
@@ -0,0 +1,81 @@
+package rtree
+
+import (
+	"github.com/kjkrol/gokq/pkg/dfs"
+	"github.com/kjkrol/gokq/pkg/qtree"
+)
+
+// findLeaf returns the leaf holding item and its index there, searching only
+// the nodes whose bounds contain the item's bounds.
+func (t *RTree[T]) findLeaf(item qtree.Item[T]) (*node[T], int) {
+	box := item.Bound()
+	var leaf *node[T]
+	index := -1
+	dfs.DFS(t.root, struct{}{}, func(n *node[T], _ struct{}) (dfs.DFSControl, struct{}) {
+		if !n.bounds.Contains(box) {
+			return dfs.SkipChildren, struct{}{}
+		}
+		for i, it := range n.items {
+			if it == item {
+				leaf, index = n, i
+				return dfs.Stop, struct{}{}
+			}
+		}
+		return dfs.Continue, struct{}{}
+	})
+	return leaf, index
+}
+
+// condense walks from n to the root after a removal, detaching nodes that
+// underflowed, and then reinserts their contents.
+func (t *RTree[T]) condense(n *node[T]) {
+	orphans := []*node[T]{}
+	for n != t.root {
+		parent := n.parent
+		if n.entries() < t.minEntries {
+			for i, child := range parent.childs {
+				if child == n {
+					parent.childs = append(parent.childs[:i], parent.childs[i+1:]...)
+					break
+				}
+			}
+			n.parent = nil
+			orphans = append(orphans, n)
+		} else {
+			n.recomputeBounds()
+		}
+		n = parent
+	}
+	t.root.recomputeBounds()
+
+	for !t.root.isLeaf() && len(t.root.childs) <= 1 {
+		if len(t.root.childs) == 0 {
+			t.root = &node[T]{}
+			break
+		}
+		t.root = t.root.childs[0]
+		t.root.parent = nil
+	}
+
+	for _, orphan := range orphans {
+		t.reattach(orphan)
+	}
+}
+
+// reattach reinserts the contents of a detached node: its child subtrees where
+// the tree is still tall enough to hold them, otherwise their items.
+func (t *RTree[T]) reattach(orphan *node[T]) {
+	if orphan.isLeaf() {
+		for _, item := range orphan.items {
+			t.insertItem(item, make(map[int]bool))
+		}
+		return
+	}
+	for _, child := range orphan.childs {
+		if child.level < t.root.level {
+			t.insertNode(child, make(map[int]bool))
+			continue
+		}
+		t.reattach(child)
+	}
+}
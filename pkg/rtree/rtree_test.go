@@ -0,0 +1,248 @@
+package rtree
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/kjkrol/gokg/pkg/geom"
+	"github.com/kjkrol/gokg/pkg/plane"
+	"github.com/kjkrol/gokq/pkg/qtree"
+	"github.com/kjkrol/gokq/pkg/qtree/qtreetest"
+	"github.com/kjkrol/goku/pkg/sliceutils"
+)
+
+func TestRTree_Conformance(t *testing.T) {
+	qtreetest.Run(t, func(space plane.Space2D[float64]) qtree.SpatialIndex[float64] {
+		return NewRTree(space, WithMaxEntries[float64](6))
+	})
+}
+
+// newPanels returns rectangles of widely differing sizes, most of them
+// overlapping the centre of the plane, like stacked UI panels.
+func newPanels(rnd *rand.Rand, n int, extent float64) []qtree.Item[float64] {
+	panels := make([]qtree.Item[float64], n)
+	for i := range panels {
+		w := math.Min(extent, math.Exp(rnd.Float64()*math.Log(extent)))
+		h := math.Min(extent, math.Exp(rnd.Float64()*math.Log(extent)))
+		x, y := rnd.Float64()*(extent-w), rnd.Float64()*(extent-h)
+		panels[i] = qtreetest.NewItem(i, x, y, w, h)
+	}
+	return panels
+}
+
+func verifyInvariants[T geom.Numeric](t *testing.T, tree *RTree[T], strictFill bool) {
+	t.Helper()
+	count := 0
+	var check func(n *node[T])
+	check = func(n *node[T]) {
+		if n.entries() > tree.maxEntries {
+			t.Fatalf("node at level %d holds %d entries, more than %d", n.level, n.entries(), tree.maxEntries)
+		}
+		if strictFill && n != tree.root && n.entries() < tree.minEntries {
+			t.Fatalf("node at level %d holds %d entries, fewer than %d", n.level, n.entries(), tree.minEntries)
+		}
+		expected := n.bounds
+		n.recomputeBounds()
+		if n.bounds != expected {
+			t.Fatalf("node bounds %v are not tight, expected %v", expected, n.bounds)
+		}
+		count += len(n.items)
+		for _, child := range n.childs {
+			if child.parent != n || child.level != n.level-1 {
+				t.Fatalf("child at level %d is not linked to its parent at level %d", child.level, n.level)
+			}
+			check(child)
+		}
+	}
+	check(tree.root)
+	if count != tree.Count() {
+		t.Fatalf("tree holds %d items, Count() reports %d", count, tree.Count())
+	}
+}
+
+func TestRTree_InsertRemoveKeepInvariants(t *testing.T) {
+	tree := NewRTree(plane.NewEuclidean2D(1000.0, 1000.0), WithMaxEntries[float64](8))
+	defer tree.Close()
+
+	rnd := rand.New(rand.NewSource(38))
+	panels := newPanels(rnd, 2000, 1000)
+	for _, panel := range panels {
+		tree.Add(panel)
+	}
+	verifyInvariants(t, tree, true)
+	if tree.Depth() < 3 {
+		t.Fatalf("expected a tree of several levels, got depth %d", tree.Depth())
+	}
+
+	for _, i := range rnd.Perm(len(panels))[:1500] {
+		if !tree.Remove(panels[i]) {
+			t.Fatalf("failed to remove %v", panels[i])
+		}
+	}
+	verifyInvariants(t, tree, true)
+	if tree.Count() != 500 {
+		t.Fatalf("expected 500 items, got %d", tree.Count())
+	}
+}
+
+func TestRTree_BatchUpdateFindsMovedItems(t *testing.T) {
+	tree := NewRTree(plane.NewEuclidean2D(1000.0, 1000.0), WithMaxEntries[float64](6))
+	defer tree.Close()
+
+	rnd := rand.New(rand.NewSource(38))
+	panels := newPanels(rnd, 400, 1000)
+	for _, panel := range panels {
+		tree.Add(panel)
+	}
+
+	// Moved items are only found by the full scan, and so are stand-ins that
+	// share nothing with the stored item but its ID.
+	toRemove := make([]qtree.Item[float64], 0, 150)
+	for i, panel := range panels[:150] {
+		switch i % 3 {
+		case 0:
+			toRemove = append(toRemove, panel)
+		case 1:
+			moved := panel.(*qtreetest.Item)
+			moved.AABB = geom.NewAABBAt(geom.NewVec(rnd.Float64()*990, rnd.Float64()*990), 10, 10)
+			toRemove = append(toRemove, moved)
+		default:
+			toRemove = append(toRemove, qtreetest.NewItem(panel.(*qtreetest.Item).ID, 0, 0, 1, 1))
+		}
+	}
+	tree.BatchUpdate(toRemove, nil, false)
+
+	verifyInvariants(t, tree, true)
+	if got := tree.AllItems(); !sliceutils.SameElements(got, panels[150:]) {
+		t.Errorf("AllItems returned %d items after the batch removal, expected %d", len(got), len(panels)-150)
+	}
+}
+
+func TestRTree_BulkLoad(t *testing.T) {
+	tree := NewRTree(plane.NewEuclidean2D(1000.0, 1000.0))
+	defer tree.Close()
+
+	panels := newPanels(rand.New(rand.NewSource(39)), 3000, 1000)
+	tree.Add(panels[0])
+	outside := qtreetest.NewItem(-1, 990, 990, 20, 20)
+	if added := tree.BulkLoad(append(slices.Clone(panels[1:]), outside)); added != len(panels)-1 {
+		t.Fatalf("BulkLoad added %d items, expected %d", added, len(panels)-1)
+	}
+	verifyInvariants(t, tree, false)
+	if !sliceutils.SameElements(tree.AllItems(), panels) {
+		t.Fatalf("bulk-loaded tree holds different items")
+	}
+	if expected := int(math.Ceil(math.Log(float64(len(panels))) / math.Log(float64(MAX_ENTRIES)))); tree.Depth() != expected {
+		t.Errorf("expected a packed tree of depth %d, got %d", expected, tree.Depth())
+	}
+}
+
+func TestRTree_QueriesMatchBruteForce(t *testing.T) {
+	for _, space := range []plane.Space2D[float64]{
+		plane.NewEuclidean2D(1000.0, 1000.0),
+		plane.NewToroidal2D(1000.0, 1000.0),
+	} {
+		t.Run(space.Name(), func(t *testing.T) {
+			tree := NewRTree(space)
+			defer tree.Close()
+			rnd := rand.New(rand.NewSource(40))
+			panels := newPanels(rnd, 1500, 1000)
+			tree.BulkLoad(panels[:1000])
+			for _, panel := range panels[1000:] {
+				tree.Add(panel)
+			}
+			cyclic := qtree.IsCyclic(space)
+
+			for range 100 {
+				pos := geom.NewVec(rnd.Float64()*1000, rnd.Float64()*1000)
+				area := geom.NewAABBAround(pos, rnd.Float64()*100)
+				fragments := fragmentsOf(space.WrapAABB(area))
+				if !cyclic {
+					fragments = []geom.AABB[float64]{area}
+				}
+				expected := []qtree.Item[float64]{}
+				for _, panel := range panels {
+					if intersectsAny(panel.Bound(), fragments) {
+						expected = append(expected, panel)
+					}
+				}
+				if got := tree.FindInAABB(area); !sliceutils.SameElements(got, expected) {
+					t.Fatalf("FindInAABB(%v) returned %d items, expected %d", area, len(got), len(expected))
+				}
+
+				byDistance := slices.Clone(panels)
+				slices.SortStableFunc(byDistance, func(a, b qtree.Item[float64]) int {
+					return int(math.Copysign(1, tree.pointDistance(pos, a.Bound())-tree.pointDistance(pos, b.Bound())))
+				})
+				got := tree.Nearest(pos, 10)
+				if len(got) != 10 {
+					t.Fatalf("Nearest returned %d items", len(got))
+				}
+				for i := range got {
+					if tree.pointDistance(pos, got[i].Bound()) != tree.pointDistance(pos, byDistance[i].Bound()) {
+						t.Fatalf("Nearest(%v)[%d] = %v, expected %v", pos, i, got[i], byDistance[i])
+					}
+				}
+			}
+		})
+	}
+}
+
+func ExampleRTree_Nearest() {
+	tree := NewRTree(plane.NewToroidal2D(100.0, 100.0))
+	defer tree.Close()
+
+	tree.Add(qtreetest.NewItem(1, 0, 0, 100, 10))
+	tree.Add(qtreetest.NewItem(2, 40, 40, 20, 20))
+	tree.Add(qtreetest.NewItem(3, 50, 80, 4, 4))
+
+	for _, item := range tree.Nearest(geom.NewVec(50.0, 99.0), 2) {
+		fmt.Println(item.(*qtreetest.Item).ID)
+	}
+
+	// Output:
+	// 1
+	// 3
+}
+
+var rtreeBenchSink int
+
+func Benchmark_RTree_vs_QuadTree_OverlappingPanels(b *testing.B) {
+	space := plane.NewEuclidean2D(1000.0, 1000.0)
+	panels := newPanels(rand.New(rand.NewSource(7)), 5000, 1000)
+
+	quadtree := qtree.NewQuadTree(space)
+	defer quadtree.Close()
+	rtree := NewRTree(space)
+	defer rtree.Close()
+	for _, panel := range panels {
+		quadtree.Add(panel)
+	}
+	rtree.BulkLoad(panels)
+
+	probes := make([]qtree.Item[float64], 1000)
+	rnd := rand.New(rand.NewSource(8))
+	for i := range probes {
+		probes[i] = qtreetest.NewItem(-i-1, rnd.Float64()*990, rnd.Float64()*990, 10, 10)
+	}
+
+	b.Run("quadtree", func(b *testing.B) {
+		b.ReportAllocs()
+		i := 0
+		for b.Loop() {
+			rtreeBenchSink += len(quadtree.FindNeighbors(probes[i%len(probes)], 0))
+			i++
+		}
+	})
+	b.Run("rtree", func(b *testing.B) {
+		b.ReportAllocs()
+		i := 0
+		for b.Loop() {
+			rtreeBenchSink += len(rtree.FindNeighbors(probes[i%len(probes)], 0))
+			i++
+		}
+	})
+}
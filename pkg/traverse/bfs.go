@@ -0,0 +1,83 @@
+// Package traverse provides breadth-first traversals complementing dfs.DFS.
+// They accept the same dfs.ChildCarrier nodes, dfs.DFSControl values and
+// per-branch accumulator semantics, so a step function written for dfs.DFS
+// can be reused unchanged.
+package traverse
+
+import (
+	"github.com/kjkrol/gokq/pkg/dfs"
+)
+
+// LevelFunc is called at every depth boundary, before the first node of depth
+// is visited, with the number of nodes on that level; the root is at depth 0.
+// Returning false stops the traversal.
+type LevelFunc func(depth, width int) bool
+
+// BFS walks the structure rooted at root level by level using a FIFO queue.
+// As in dfs.DFS, each node receives the accumulator returned by its parent's
+// step, SkipChildren keeps a node's children out of the queue and Stop ends
+// the traversal immediately. BFS reports whether it was stopped and how many
+// nodes were visited.
+func BFS[N dfs.ChildCarrier[N], A any](
+	root N,
+	accInitial A,
+	step dfs.DFSStepFunc[N, A],
+) (stopped bool, visited int) {
+	return BFSLevels(root, accInitial, step, nil)
+}
+
+// BFSLevels is BFS with an additional level callback reporting depth
+// boundaries, e.g. to process a tree level by level for LOD rendering. A level
+// callback returning false stops the traversal like Stop does.
+func BFSLevels[N dfs.ChildCarrier[N], A any](
+	root N,
+	accInitial A,
+	step dfs.DFSStepFunc[N, A],
+	level LevelFunc,
+) (stopped bool, visited int) {
+	type frame struct {
+		node N
+		acc  A
+	}
+	current := []frame{{root, accInitial}}
+	for depth := 0; len(current) > 0; depth++ {
+		if level != nil && !level(depth, len(current)) {
+			return true, visited
+		}
+		next := []frame{}
+		for _, entry := range current {
+			control, nextAcc := step(entry.node, entry.acc)
+			visited++
+
+			switch control {
+			case dfs.Stop:
+				return true, visited
+			case dfs.SkipChildren:
+				continue
+			}
+
+			for _, child := range entry.node.Children() {
+				next = append(next, frame{child, nextAcc})
+			}
+		}
+		current = next
+	}
+	return false, visited
+}
+
+// AtDepth returns the nodes at the given depth below root, in breadth-first
+// order; the root alone is at depth 0.
+func AtDepth[N dfs.ChildCarrier[N]](root N, depth int) []N {
+	if depth < 0 {
+		return nil
+	}
+	level := []N{root}
+	for range depth {
+		next := []N{}
+		for _, node := range level {
+			next = append(next, node.Children()...)
+		}
+		level = next
+	}
+	return level
+}
@@ -0,0 +1,108 @@
+package traverse
+
+import (
+	"fmt"
+
+	"github.com/kjkrol/gokq/pkg/dfs"
+)
+
+type exampleNode struct {
+	label    string
+	children []*exampleNode
+}
+
+func (n *exampleNode) Children() []*exampleNode {
+	return n.children
+}
+
+func leaf(label string) *exampleNode {
+	return &exampleNode{label: label}
+}
+
+func branch(label string, children ...*exampleNode) *exampleNode {
+	return &exampleNode{label: label, children: children}
+}
+
+func exampleTree() *exampleNode {
+	return branch("root",
+		branch("a", leaf("a1"), leaf("a2")),
+		branch("b", branch("b1", leaf("b1x"))),
+		leaf("c"),
+	)
+}
+
+// ExampleBFS shows that nodes are visited level by level and that every node
+// receives the accumulator of its own branch.
+func ExampleBFS() {
+	BFS(exampleTree(), "", func(node *exampleNode, path string) (dfs.DFSControl, string) {
+		path = path + "/" + node.label
+		fmt.Println(path)
+		if node.label == "a" {
+			return dfs.SkipChildren, path
+		}
+		return dfs.Continue, path
+	})
+
+	// Output:
+	// /root
+	// /root/a
+	// /root/b
+	// /root/c
+	// /root/b/b1
+	// /root/b/b1/b1x
+}
+
+// ExampleBFSLevels reports the depth boundaries and stops below the second
+// level.
+func ExampleBFSLevels() {
+	BFSLevels(exampleTree(), struct{}{},
+		func(node *exampleNode, _ struct{}) (dfs.DFSControl, struct{}) {
+			fmt.Println(" ", node.label)
+			return dfs.Continue, struct{}{}
+		},
+		func(depth, width int) bool {
+			if depth > 1 {
+				return false
+			}
+			fmt.Printf("depth %d (%d nodes)\n", depth, width)
+			return true
+		},
+	)
+
+	// Output:
+	// depth 0 (1 nodes)
+	//   root
+	// depth 1 (3 nodes)
+	//   a
+	//   b
+	//   c
+}
+
+func ExampleAtDepth() {
+	for _, node := range AtDepth(exampleTree(), 2) {
+		fmt.Println(node.label)
+	}
+
+	// Output:
+	// a1
+	// a2
+	// b1
+}
+
+// ExampleBFS_stop shows that Stop ends the traversal in the middle of a level.
+func ExampleBFS_stop() {
+	stopped, visited := BFS(exampleTree(), struct{}{}, func(node *exampleNode, _ struct{}) (dfs.DFSControl, struct{}) {
+		fmt.Println(node.label)
+		if node.label == "b" {
+			return dfs.Stop, struct{}{}
+		}
+		return dfs.Continue, struct{}{}
+	})
+	fmt.Println(stopped, visited)
+
+	// Output:
+	// root
+	// a
+	// b
+	// true 3
+}